@@ -1,105 +1,452 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/spf13/cobra"
 	"github.com/vibhordubey333/k8s-devguardian-ai/internal/ai"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/baseline"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/opa"
 	"github.com/vibhordubey333/k8s-devguardian-ai/internal/output"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/policyreport"
 	"github.com/vibhordubey333/k8s-devguardian-ai/internal/scanner"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/suppression"
+	"k8s.io/client-go/dynamic"
 	"os"
+	"strings"
+	"time"
 )
 
 var (
-	outputFormat string
-	aiProvider   string
-	apiKey       string
-	modelName    string
-	ollamaURL    string
-	outputFile   string
+	outputFormats []string
+	aiProvider    string
+	apiKey        string
+	modelName     string
+	ollamaURL     string
+	outputFile    string
+	policyPaths   []string
+	bundlePaths   []string
+	bundleKey     string
+	bundleAlg     string
+	engine        string
+	enableChecks  []string
+	disableChecks []string
+	useWasm       bool
+	policyData    []string
+	clusterName   string
+	minSeverity   string
+	failOn        string
+	failOnCount   int
+	ignoreFile    string
+	baselinePath  string
+	writeBaseline bool
+	showFixed     bool
+	manifestPaths []string
+	maxLength     int
+	applyReports  bool
+	columns       []string
+	templatePath  string
+)
+
+// Exit codes of the audit command
+const (
+	// exitClean means the scan completed and no findings reached the
+	// --fail-on threshold
+	exitClean = 0
+	// exitError means the audit could not run, such as when the cluster is
+	// unreachable or a flag or policy is invalid
+	exitError = 1
+	// exitFindings means findings reached the --fail-on threshold
+	exitFindings = 2
+	// exitPartial means parts of the cluster could not be scanned, such as a
+	// resource kind the user may not list, and no findings reached the
+	// threshold in the rest
+	exitPartial = 3
 )
 
 var auditCmd = &cobra.Command{
 	Use:   "audit",
 	Short: "Audits K8s cluster",
-	Long:  `Performs a security audit on your Kubernetes cluster and provides AI-powered explanations and remediation suggestions.`,
+	Long: `Performs a security audit on your Kubernetes cluster and provides AI-powered explanations and remediation suggestions.
+
+Exit codes:
+  0  the scan completed and no findings reached the --fail-on threshold
+  1  the audit could not run (unreachable cluster, invalid flag, policy or config)
+  2  findings reached the --fail-on or --fail-on-count threshold
+  3  parts of the cluster could not be scanned (e.g. a resource kind is forbidden)`,
+	Example: `  devguardian audit --fail-on high
+  devguardian audit --manifests ./deploy --output sarif --file devguardian.sarif
+  devguardian audit --apply
+  devguardian audit --output json=report.json --output html=report.html --output cli
+  devguardian audit --baseline baseline.json --write-baseline
+  devguardian audit --baseline baseline.json --show-fixed --fail-on high
+  devguardian audit --fail-on medium --fail-on-count 10 --output json --file report.json`,
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("🕵️ Running cluster audit...")
 
-		// Scan the cluster
-		findings, err := scanner.ScanCluster()
+		var minimum, threshold auditor.Severity
+		if minSeverity != "" {
+			var err error
+			if minimum, err = auditor.ParseSeverity(minSeverity); err != nil {
+				fmt.Printf("❌ Invalid --min-severity: %v\n", err)
+				os.Exit(exitError)
+			}
+		}
+		if failOn != "" {
+			var err error
+			if threshold, err = auditor.ParseSeverity(failOn); err != nil {
+				fmt.Printf("❌ Invalid --fail-on: %v\n", err)
+				os.Exit(exitError)
+			}
+		}
+		if failOnCount < 0 {
+			fmt.Printf("❌ Invalid --fail-on-count: %d is negative\n", failOnCount)
+			os.Exit(exitError)
+		}
+		if (writeBaseline || showFixed) && baselinePath == "" {
+			fmt.Println("❌ --write-baseline and --show-fixed require --baseline")
+			os.Exit(exitError)
+		}
+		if err := output.ValidateColumns(columns); err != nil {
+			fmt.Printf("❌ Invalid --columns: %v\n", err)
+			os.Exit(exitError)
+		}
+		outputs, err := parseOutputs(outputFormats, outputFile)
+		if err != nil {
+			fmt.Printf("❌ Invalid --output: %v\n", err)
+			os.Exit(exitError)
+		}
+		usesTemplate := false
+		for _, out := range outputs {
+			usesTemplate = usesTemplate || out.format == output.FormatTemplate
+		}
+		if usesTemplate != (templatePath != "") {
+			fmt.Println("❌ --output template and --template must be used together")
+			os.Exit(exitError)
+		}
+		if applyReports && len(manifestPaths) > 0 {
+			fmt.Println("❌ --apply writes the findings of a cluster scan and cannot be used with --manifests")
+			os.Exit(exitError)
+		}
+
+		cfg := loadConfig()
+		params, err := cfg.PolicyParams(policyData)
 		if err != nil {
+			fmt.Printf("❌ Error loading policy data: %v\n", err)
+			os.Exit(exitError)
+		}
+
+		suppressions, err := suppression.Load(ignoreFile)
+		if err != nil {
+			fmt.Printf("❌ Error loading suppressions: %v\n", err)
+			os.Exit(exitError)
+		}
+
+		// Scan the cluster, or the manifests instead
+		scanOptions := scanner.Options{
+			PolicyPaths:        policyPaths,
+			PolicyBundles:      bundlePaths,
+			BundleOptions:      opa.BundleOptions{PublicKey: bundleKey, SigningAlg: bundleAlg},
+			Engine:             scanner.Engine(engine),
+			EnableChecks:       enableChecks,
+			DisableChecks:      disableChecks,
+			Wasm:               useWasm,
+			Params:             params,
+			Cluster:            clusterName,
+			Suppressions:       suppressions,
+			DisableAnnotations: !cfg.Suppressions.AnnotationsEnabled(),
+		}
+		var findings []auditor.AuditFinding
+		if len(manifestPaths) > 0 {
+			findings, err = scanner.ScanManifests(manifestPaths, scanOptions)
+		} else {
+			findings, err = scanner.ScanCluster(scanOptions)
+		}
+		var partial *scanner.PartialError
+		if errors.As(err, &partial) {
+			fmt.Printf("⚠️ Warning: The scan is incomplete, %d part(s) could not be scanned:\n", len(partial.Errors))
+			for _, scanErr := range partial.Errors {
+				fmt.Printf("  - %v\n", scanErr)
+			}
+		} else if err != nil {
 			fmt.Printf("❌ Error during scan: %v\n", err)
-			os.Exit(1)
+			os.Exit(exitError)
 		}
 
-		fmt.Printf("✅ Scan completed! Found %d potential security issues.\n", len(findings))
+		// Write every finding to the cluster as PolicyReports, before the
+		// baseline and --min-severity narrow down the report: findings in the
+		// baseline are accepted for now but still open, and dashboards must
+		// not show them as fixed. Reports of namespaces without findings are
+		// only pruned after a complete scan.
+		if applyReports {
+			if err := applyPolicyReports(findings, partial == nil); err != nil {
+				fmt.Printf("❌ Error applying policy reports: %v\n", err)
+				os.Exit(exitError)
+			}
+			fmt.Println("✅ Policy reports applied to the cluster")
+		}
 
-		// If no findings, exit early
-		if len(findings) == 0 {
-			fmt.Println("🎉 No security issues found!")
-			return
+		// Compare with the baseline, or record it
+		var fixed []auditor.AuditFinding
+		known := 0
+		switch {
+		case writeBaseline && partial != nil:
+			fmt.Println("⚠️ Warning: Not writing the baseline, as the scan is incomplete")
+		case writeBaseline:
+			if err := baseline.Write(baselinePath, findings); err != nil {
+				fmt.Printf("❌ Error writing baseline: %v\n", err)
+				os.Exit(exitError)
+			}
+			fmt.Printf("✅ Baseline of %d finding(s) written to %s\n", len(findings), baselinePath)
+		case baselinePath != "":
+			previous, err := baseline.Load(baselinePath)
+			if err != nil {
+				fmt.Printf("❌ Error loading baseline: %v\n", err)
+				os.Exit(exitError)
+			}
+			var added []auditor.AuditFinding
+			added, fixed = baseline.Compare(previous, findings)
+			known = len(findings) - len(added)
+			fmt.Printf("📌 %d finding(s) are in the baseline, %d are new and %d fixed\n", known, len(added), len(fixed))
+			findings = added
+			if !showFixed {
+				fixed = nil
+			}
 		}
 
-		// Initialize AI explainer
-		fmt.Println("🧠 Analyzing findings with AI...")
-		explainer, err := ai.NewExplainer(ai.ExplainerConfig{
-			Provider:  aiProvider,
-			APIKey:    apiKey,
-			ModelName: modelName,
-			OllamaURL: ollamaURL,
-		})
-		if err != nil {
-			fmt.Printf("⚠️ Warning: Could not initialize AI explainer: %v\n", err)
-			fmt.Println("⚠️ Continuing with basic explanations...")
-			explainer = ai.NewSimpleExplainer()
+		if minimum != "" {
+			findings = auditor.FilterBySeverity(findings, minimum)
+			fixed = auditor.FilterBySeverity(fixed, minimum)
 		}
 
-		// Get explanations for findings
-		explanations, err := explainer.ExplainFindings(findings)
-		if err != nil {
-			fmt.Printf("⚠️ Warning: Error getting AI explanations: %v\n", err)
-			fmt.Println("⚠️ Continuing with basic explanations...")
-			explainer = ai.NewSimpleExplainer()
-			explanations, _ = explainer.ExplainFindings(findings)
+		// Suppressed findings are reported but not explained, and never fail
+		// the audit
+		active := auditor.Unsuppressed(findings)
+		fmt.Printf("✅ Scan completed! Found %d potential security issues.\n", len(active))
+		if suppressed := len(findings) - len(active); suppressed > 0 {
+			fmt.Printf("🔕 %d finding(s) suppressed as accepted risks\n", suppressed)
 		}
 
-		// Format the output
+		// Only active findings are explained. A clean audit still writes its
+		// reports, so that CI systems find an empty but valid report.
+		explanations := []ai.FindingExplanation{}
+		if findings == nil {
+			findings = []auditor.AuditFinding{}
+		}
+		if len(active) == 0 {
+			fmt.Println("🎉 No security issues found!")
+		} else {
+			explanations = explainFindings(active)
+		}
+
+		// Format the reports. A report that cannot be written does not stop
+		// the others, but fails the audit.
 		fmt.Println("📊 Generating report...")
-		formatter := output.NewFormatter(output.Format(outputFormat))
 		result := output.AuditResult{
 			Findings:     findings,
 			Explanations: explanations,
 			Summary:      output.GenerateSummary(findings),
+			Fixed:        fixed,
 		}
+		result.Summary.Baseline = known
 
-		reportBytes, err := formatter.Format(result)
-		if err != nil {
-			fmt.Printf("❌ Error formatting report: %v\n", err)
-			os.Exit(1)
+		failed := false
+		for _, out := range outputs {
+			if err := writeReport(out, result); err != nil {
+				fmt.Printf("❌ Error writing %s report: %v\n", out.format, err)
+				failed = true
+			}
+		}
+		if failed {
+			os.Exit(exitError)
 		}
 
-		// Output the report
-		if outputFile != "" {
-			err := os.WriteFile(outputFile, reportBytes, 0644)
-			if err != nil {
-				fmt.Printf("❌ Error writing report to file: %v\n", err)
-				os.Exit(1)
+		os.Exit(auditExitCode(active, threshold, partial != nil))
+	},
+}
+
+// explainFindings explains the findings with the configured AI provider,
+// falling back to basic explanations if it is unavailable
+func explainFindings(findings []auditor.AuditFinding) []ai.FindingExplanation {
+	fmt.Println("🧠 Analyzing findings with AI...")
+	explainer, err := ai.NewExplainer(ai.ExplainerConfig{
+		Provider:  aiProvider,
+		APIKey:    apiKey,
+		ModelName: modelName,
+		OllamaURL: ollamaURL,
+	})
+	if err != nil {
+		fmt.Printf("⚠️ Warning: Could not initialize AI explainer: %v\n", err)
+		fmt.Println("⚠️ Continuing with basic explanations...")
+		explainer = ai.NewSimpleExplainer()
+	}
+
+	explanations, err := explainer.ExplainFindings(findings)
+	if err != nil {
+		fmt.Printf("⚠️ Warning: Error getting AI explanations: %v\n", err)
+		fmt.Println("⚠️ Continuing with basic explanations...")
+		explainer = ai.NewSimpleExplainer()
+		explanations, _ = explainer.ExplainFindings(findings)
+	}
+	return explanations
+}
+
+// auditOutput is a report of the audit and where it is written
+type auditOutput struct {
+	format output.Format
+	path   string // File the report is written to, or empty for stdout
+}
+
+// parseOutputs parses the --output flags, each a format optionally followed
+// by =path. For compatibility, a single output without a path is written to
+// --file. At most one report can be written to stdout, and formats cannot be
+// repeated for the same destination.
+func parseOutputs(values []string, file string) ([]auditOutput, error) {
+	var outputs []auditOutput
+	stdout := 0
+	seen := make(map[auditOutput]bool)
+	for _, value := range values {
+		name, path, _ := strings.Cut(value, "=")
+		format, err := output.ParseFormat(name)
+		if err != nil {
+			return nil, err
+		}
+		if strings.Contains(value, "=") && path == "" {
+			return nil, fmt.Errorf("%q has no path after =", value)
+		}
+		out := auditOutput{format: format, path: path}
+		if path == "" {
+			if file != "" && len(values) == 1 {
+				out.path = file
+			} else {
+				stdout++
 			}
-			fmt.Printf("✅ Report saved to %s\n", outputFile)
-		} else {
-			fmt.Println(string(reportBytes))
 		}
-	},
+		if seen[out] {
+			return nil, fmt.Errorf("%q is given twice", value)
+		}
+		seen[out] = true
+		outputs = append(outputs, out)
+	}
+	if file != "" && len(values) > 1 {
+		return nil, fmt.Errorf("--file can only be used with a single --output, use --output format=path instead")
+	}
+	if stdout > 1 {
+		return nil, fmt.Errorf("only one report can be written to stdout, use --output format=path for the others")
+	}
+	return outputs, nil
+}
+
+// writeReport formats the result and writes it to the output's file, or to
+// stdout
+func writeReport(out auditOutput, result output.AuditResult) error {
+	formatter := output.NewFormatter(out.format)
+	switch f := formatter.(type) {
+	case *output.MarkdownFormatter:
+		f.MaxLength = maxLength
+	case *output.TableFormatter:
+		f.Columns = columns
+	case *output.TemplateFormatter:
+		f.Path = templatePath
+	}
+	reportBytes, err := formatter.Format(result)
+	if err != nil {
+		return fmt.Errorf("failed to format report: %w", err)
+	}
+
+	if out.path == "" {
+		fmt.Println(string(reportBytes))
+		return nil
+	}
+	if err := os.WriteFile(out.path, reportBytes, 0644); err != nil {
+		return fmt.Errorf("failed to write report to file: %w", err)
+	}
+	fmt.Printf("✅ Report saved to %s\n", out.path)
+	return nil
+}
+
+// auditExitCode returns the exit code of an audit that reported findings.
+// With --fail-on or --fail-on-count the audit fails when at least
+// --fail-on-count findings (1 by default) are of the --fail-on severity or
+// higher (any severity by default). A failing threshold takes precedence over
+// an incomplete scan, as the gate fails either way.
+func auditExitCode(findings []auditor.AuditFinding, threshold auditor.Severity, partial bool) int {
+	if threshold != "" || failOnCount > 0 {
+		failing := len(findings)
+		if threshold != "" {
+			failing = len(auditor.FilterBySeverity(findings, threshold))
+		}
+		limit := failOnCount
+		if limit == 0 {
+			limit = 1
+		}
+		if failing >= limit {
+			fmt.Printf("❌ %d finding(s) at or above the --fail-on threshold (%s), failing the audit\n", failing, thresholdLabel(threshold))
+			return exitFindings
+		}
+	}
+	if partial {
+		return exitPartial
+	}
+	return exitClean
+}
+
+// applyPolicyReports writes the findings to the current kubeconfig context as
+// PolicyReports and a ClusterPolicyReport with server-side apply
+func applyPolicyReports(findings []auditor.AuditFinding, prune bool) error {
+	config, err := scanner.RESTConfig()
+	if err != nil {
+		return err
+	}
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	return policyreport.Apply(ctx, client, policyreport.Build(findings, time.Now()), prune)
+}
+
+// thresholdLabel describes the --fail-on severity
+func thresholdLabel(threshold auditor.Severity) string {
+	if threshold == "" {
+		return "any severity"
+	}
+	return string(threshold)
 }
 
 func init() {
 	rootCmd.AddCommand(auditCmd)
 
 	// Add flags
-	auditCmd.Flags().StringVarP(&outputFormat, "output", "o", "cli", "Output format (cli, json, html)")
+	auditCmd.Flags().StringArrayVarP(&outputFormats, "output", "o", []string{"cli"}, "Output format (cli, json, html, sarif, junit, markdown, policyreport, csv, tsv, template), optionally followed by =path to write it to a file (repeatable)")
+	auditCmd.Flags().StringVar(&templatePath, "template", "", "Go template rendering the report with --output template (html/template for .html files, text/template otherwise)")
+	auditCmd.Flags().StringSliceVar(&columns, "columns", nil, "Columns of CSV and TSV reports, in order (default "+strings.Join(output.DefaultColumns, ",")+")")
+	auditCmd.Flags().IntVar(&maxLength, "max-length", 0, "Maximum length in bytes of Markdown reports, such as 65536 for GitHub comments (0 for no limit)")
 	auditCmd.Flags().StringVarP(&aiProvider, "ai-provider", "a", "", "AI provider (openai, ollama)")
 	auditCmd.Flags().StringVarP(&apiKey, "api-key", "k", "", "API key for OpenAI")
 	auditCmd.Flags().StringVarP(&modelName, "model", "m", "", "Model name to use")
 	auditCmd.Flags().StringVarP(&ollamaURL, "ollama-url", "u", "http://localhost:11434", "URL for Ollama server")
-	auditCmd.Flags().StringVarP(&outputFile, "file", "f", "", "Output file path")
+	auditCmd.Flags().StringVarP(&outputFile, "file", "f", "", "Output file path, with a single --output")
+	auditCmd.Flags().StringSliceVarP(&policyPaths, "policy", "p", nil, "Policy file or directory with Rego, Gatekeeper or Kyverno policies (repeatable, default internal/policies)")
+	auditCmd.Flags().StringVar(&engine, "engine", string(scanner.EngineGo), "Engine running the built-in checks (go, rego)")
+	auditCmd.Flags().StringSliceVar(&enableChecks, "enable", nil, "Built-in checks to run, by ID (wildcards allowed, default all)")
+	auditCmd.Flags().StringSliceVar(&disableChecks, "disable", nil, "Built-in checks to skip, by ID (wildcards allowed)")
+	auditCmd.Flags().StringSliceVar(&bundlePaths, "policy-bundle", nil, "OPA bundle tarball, directory, http(s) URL or pre-built .wasm module (repeatable)")
+	auditCmd.Flags().StringVar(&bundleKey, "policy-bundle-key", "", "Public key used to verify policy bundle signatures")
+	auditCmd.Flags().StringVar(&bundleAlg, "policy-bundle-alg", opa.DefaultBundleSigningAlg, "Signature algorithm of the policy bundles")
+	auditCmd.Flags().StringSliceVar(&policyData, "policy-data", nil, "JSON or YAML file loaded into data.params for the policies (repeatable, later files override earlier ones)")
+	auditCmd.Flags().StringVar(&ignoreFile, "ignore-file", "", "Suppression file of accepted risks (default is "+suppression.DefaultFile+" in the working directory, if present)")
+	auditCmd.Flags().StringVar(&baselinePath, "baseline", "", "JSON report of a previous audit; only findings that are not in it are reported")
+	auditCmd.Flags().BoolVar(&writeBaseline, "write-baseline", false, "Write the current findings to the --baseline file instead of comparing with it")
+	auditCmd.Flags().BoolVar(&showFixed, "show-fixed", false, "Also report the findings of the --baseline that are no longer found")
+	auditCmd.Flags().BoolVar(&applyReports, "apply", false, "Also write the findings to the cluster as wgpolicyk8s.io PolicyReports with server-side apply")
+	auditCmd.Flags().StringSliceVar(&manifestPaths, "manifests", nil, "Scan the objects in these manifest files or directories instead of the cluster (repeatable, - for stdin)")
+	auditCmd.Flags().StringVar(&clusterName, "cluster-name", "", "Cluster name recorded on the findings and their fingerprints (default is the cluster of the current kubeconfig context)")
+	auditCmd.Flags().StringVar(&minSeverity, "min-severity", "", "Only report findings of this severity or higher (info, low, medium, high, critical)")
+	auditCmd.Flags().StringVar(&failOn, "fail-on", "", "Exit with code 2 if any finding is of this severity or higher (info, low, medium, high, critical)")
+	auditCmd.Flags().IntVar(&failOnCount, "fail-on-count", 0, "Exit with code 2 only if at least this many findings reach the --fail-on severity (any severity if --fail-on is not set)")
+	auditCmd.Flags().BoolVar(&useWasm, "wasm", false, "Compile the Rego policies to Wasm and evaluate them with the OPA Wasm runtime (requires a build with -tags opa_wasm)")
 }
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runAudit runs the audit command with args in a child process, as the
+// command exits, and returns its exit code and output
+func runAudit(t *testing.T, dir string, args ...string) (int, string) {
+	t.Helper()
+	return runCommand(t, dir, append([]string{"audit"}, args...)...)
+}
+
+// runCommand runs the root command with args in a child process and returns
+// its exit code and output
+func runCommand(t *testing.T, dir string, args ...string) (int, string) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestCommandHelper$")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "DEVGUARDIAN_ARGS="+strings.Join(args, "\n"))
+	out, err := cmd.CombinedOutput()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode(), string(out)
+	} else if err != nil {
+		t.Fatalf("Failed to run the command: %v", err)
+	}
+	return 0, string(out)
+}
+
+// TestCommandHelper runs the root command when started by runCommand
+func TestCommandHelper(t *testing.T) {
+	args := os.Getenv("DEVGUARDIAN_ARGS")
+	if args == "" {
+		t.Skip("only run by runCommand")
+	}
+	rootCmd.SetArgs(strings.Split(args, "\n"))
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(exitError)
+	}
+	os.Exit(exitClean)
+}
+
+func TestAudit_CleanScanWritesReports(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "settings.yaml"), []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: default
+data:
+  level: info
+`), 0644)
+
+	code, out := runAudit(t, dir, "--manifests", "settings.yaml",
+		"--output", "junit=report.xml", "--output", "sarif=report.sarif", "--output", "json=report.json",
+		"--output", "csv=report.csv", "--output", "policyreport=reports.yaml")
+	if code != exitClean {
+		t.Fatalf("Expected exit code %d, got %d:\n%s", exitClean, code, out)
+	}
+
+	read := func(name string) []byte {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Expected %s to be written for a clean scan, got %v\n%s", name, err, out)
+		}
+		return data
+	}
+	var junit struct {
+		XMLName xml.Name `xml:"testsuites"`
+		Tests   int      `xml:"tests,attr"`
+	}
+	if err := xml.Unmarshal(read("report.xml"), &junit); err != nil || junit.Tests != 0 {
+		t.Errorf("Expected an empty JUnit report, got %+v, %v", junit, err)
+	}
+	var sarif struct {
+		Runs []struct {
+			Results []interface{} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(read("report.sarif"), &sarif); err != nil || len(sarif.Runs) != 1 || sarif.Runs[0].Results == nil {
+		t.Errorf("Expected a SARIF run without results, got %+v, %v", sarif, err)
+	}
+	if report := string(read("report.json")); !strings.Contains(report, `"Findings": []`) {
+		t.Errorf("Expected an empty JSON report, got %s", report)
+	}
+	if report := string(read("report.csv")); report != "rule_id,severity,kind,namespace,name,container,reason,explanation,remediation,suppressed\r\n" {
+		t.Errorf("Expected a CSV header only, got %q", report)
+	}
+	if report := string(read("reports.yaml")); !strings.Contains(report, "kind: ClusterPolicyReport") {
+		t.Errorf("Expected an empty ClusterPolicyReport, got %s", report)
+	}
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/baseline"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/output"
+)
+
+var (
+	diffOutput string
+	diffFile   string
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff old.json new.json",
+	Short: "Compares two audit reports",
+	Long: `Compares the findings of two JSON audit reports, such as last week's and
+today's, by fingerprint. Each finding is classified as new, resolved,
+unchanged or severity-changed, and the diff is printed as a summary table
+followed by the changed findings, or written as JSON, Markdown or HTML.`,
+	Example: `  devguardian diff last-week.json today.json
+  devguardian diff last-week.json today.json --output markdown --file diff.md`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		formatter, err := output.NewDiffFormatter(output.Format(diffOutput))
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+		oldFindings, err := baseline.Load(args[0])
+		if err != nil {
+			fmt.Printf("❌ Error loading report: %v\n", err)
+			os.Exit(1)
+		}
+		newFindings, err := baseline.Load(args[1])
+		if err != nil {
+			fmt.Printf("❌ Error loading report: %v\n", err)
+			os.Exit(1)
+		}
+
+		diff := output.NewDiff(oldFindings, newFindings)
+		diff.OldReport, diff.NewReport = args[0], args[1]
+
+		data, err := formatter.FormatDiff(diff)
+		if err != nil {
+			fmt.Printf("❌ Error formatting diff: %v\n", err)
+			os.Exit(1)
+		}
+		if diffFile != "" {
+			if err := os.WriteFile(diffFile, data, 0644); err != nil {
+				fmt.Printf("❌ Error writing diff to file: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("✅ Diff saved to %s\n", diffFile)
+			return
+		}
+		fmt.Println(string(data))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVarP(&diffOutput, "output", "o", "cli", "Output format (cli, json, markdown, html)")
+	diffCmd.Flags().StringVarP(&diffFile, "file", "f", "", "Output file path")
+}
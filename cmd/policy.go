@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Work with Rego policies",
+	Long:  `Commands for authoring, testing and debugging the Rego policies used by the audit.`,
+}
+
+func init() {
+	rootCmd.AddCommand(policyCmd)
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/kyverno"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/manifest"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/opa"
+)
+
+var (
+	evalFiles    []string
+	evalPolicies []string
+	evalExplain  string
+	evalBundles  []string
+	evalKey      string
+	evalAlg      string
+	evalData     []string
+)
+
+var policyEvalCmd = &cobra.Command{
+	Use:   "eval",
+	Short: "Evaluates manifests against policies",
+	Long: `Evaluates the objects in one or more manifest files against the Rego,
+Gatekeeper and Kyverno policies and prints the violations for each object. With --explain the OPA
+evaluation trace is printed as well, to help debug policies that misfire.
+
+All objects passed with --file are added to data.inventory, so cross-resource
+policies can be evaluated by passing the related objects together.`,
+	Example: `  devguardian policy eval -f pod.yaml
+  devguardian policy eval -f pod.yaml --policy ./policies --explain fails
+  devguardian policy eval -f pod.yaml --policy ./policies --policy-data prod-params.yaml
+  devguardian policy eval -f pod.yaml --policy-bundle bundle.tar.gz --policy-bundle-key public.pem
+  kubectl get pod web -o yaml | devguardian policy eval -f - --explain full`,
+	Run: func(cmd *cobra.Command, args []string) {
+		explain, err := opa.ParseExplainMode(evalExplain)
+		if err != nil {
+			fmt.Printf("❌ Invalid --explain: %v\n", err)
+			os.Exit(1)
+		}
+
+		objects, err := manifest.Load(evalFiles)
+		if err != nil {
+			fmt.Printf("❌ Error reading manifests: %v\n", err)
+			os.Exit(1)
+		}
+
+		inventory := opa.NewInventory()
+		for _, obj := range objects {
+			inventory.Add(obj.Object)
+		}
+
+		bundles, err := opa.LoadBundles(evalBundles, opa.BundleOptions{PublicKey: evalKey, SigningAlg: evalAlg})
+		if err != nil {
+			fmt.Printf("❌ Error loading policy bundles: %v\n", err)
+			os.Exit(1)
+		}
+
+		params, err := loadConfig().PolicyParams(evalData)
+		if err != nil {
+			fmt.Printf("❌ Error loading policy data: %v\n", err)
+			os.Exit(1)
+		}
+
+		evaluator, err := opa.NewEvaluatorWithOptions(evalPolicies, opa.EvaluatorOptions{
+			Inventory: inventory,
+			Bundles:   bundles,
+			Params:    params,
+		})
+		if err != nil {
+			fmt.Printf("❌ Error loading policies: %v\n", err)
+			os.Exit(1)
+		}
+		for _, id := range params.UnknownRules(evaluator.Rules()) {
+			fmt.Printf("⚠️ Warning: Parameters are set for %s, which is not a policy rule\n", id)
+		}
+
+		kyvernoPolicies, err := kyverno.Load(evalPolicies)
+		if err != nil {
+			fmt.Printf("❌ Error loading Kyverno policies: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, obj := range objects {
+			violations, trace, err := evaluator.Explain(obj.Object, explain)
+			if err != nil {
+				fmt.Printf("❌ Error evaluating %s: %v\n", describeObject(obj), err)
+				os.Exit(1)
+			}
+
+			for _, policy := range kyvernoPolicies {
+				findings, err := policy.Evaluate(obj.Object, inventory.NamespaceLabels(obj.Namespace()))
+				if err != nil {
+					fmt.Printf("❌ Error evaluating %s: %v\n", describeObject(obj), err)
+					os.Exit(1)
+				}
+				for _, finding := range findings {
+					violations = append(violations, opa.Violation{
+						Message:  finding.Reason,
+						Severity: finding.Severity,
+						Policy:   finding.RuleID,
+						Title:    finding.Title,
+					})
+				}
+			}
+
+			if len(violations) == 0 {
+				fmt.Printf("✅ %s: no violations\n", describeObject(obj))
+			} else {
+				fmt.Printf("❌ %s: %d violation(s)\n", describeObject(obj), len(violations))
+				for _, violation := range violations {
+					if violation.Severity != "" {
+						fmt.Printf("  - [%s] %s\n", violation.Severity, violation.Message)
+					} else {
+						fmt.Printf("  - %s\n", violation.Message)
+					}
+				}
+			}
+
+			if trace != "" {
+				fmt.Println("\n🔎 TRACE:")
+				fmt.Println(trace)
+			}
+		}
+	},
+}
+
+// describeObject returns a short Kind/namespace/name description of obj
+func describeObject(obj manifest.Object) string {
+	if obj.Namespace() == "" {
+		return fmt.Sprintf("%s/%s", obj.Kind(), obj.Name())
+	}
+	return fmt.Sprintf("%s/%s/%s", obj.Kind(), obj.Namespace(), obj.Name())
+}
+
+func init() {
+	policyCmd.AddCommand(policyEvalCmd)
+
+	policyEvalCmd.Flags().StringSliceVarP(&evalFiles, "file", "f", nil, "Manifest file or directory to evaluate (\"-\" for stdin, repeatable)")
+	policyEvalCmd.Flags().StringSliceVarP(&evalPolicies, "policy", "p", []string{opa.DefaultPolicyDir}, "Policy file or directory (repeatable)")
+	policyEvalCmd.Flags().StringSliceVar(&evalBundles, "policy-bundle", nil, "OPA bundle tarball, directory or http(s) URL (repeatable)")
+	policyEvalCmd.Flags().StringVar(&evalKey, "policy-bundle-key", "", "Public key used to verify policy bundle signatures")
+	policyEvalCmd.Flags().StringVar(&evalAlg, "policy-bundle-alg", opa.DefaultBundleSigningAlg, "Signature algorithm of the policy bundles")
+	policyEvalCmd.Flags().StringSliceVar(&evalData, "policy-data", nil, "JSON or YAML file loaded into data.params for the policies (repeatable)")
+	policyEvalCmd.Flags().StringVar(&evalExplain, "explain", string(opa.ExplainOff), "Print the evaluation trace (off, full, notes, fails)")
+	policyEvalCmd.MarkFlagRequired("file")
+}
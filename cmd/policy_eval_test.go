@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPolicyEval_InvalidExplainMode(t *testing.T) {
+	code, out := runCommand(t, t.TempDir(), "policy", "eval", "-f", "missing.yaml", "--policy", "missing", "--explain", "verbose")
+	if code != exitError {
+		t.Fatalf("Expected exit code %d, got %d:\n%s", exitError, code, out)
+	}
+	if !strings.Contains(out, `Invalid --explain: unknown explain mode "verbose"`) {
+		t.Errorf("Expected the explain mode to be rejected, got:\n%s", out)
+	}
+	if strings.Contains(out, "Error reading manifests") {
+		t.Errorf("Expected the explain mode to be checked before reading the manifests, got:\n%s", out)
+	}
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/kyverno"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/opa"
+	"github.com/vibhordubey333/k8s-devguardian-ai/pkg/checks"
+)
+
+var (
+	listOutput  string
+	listBundles []string
+	listKey     string
+	listAlg     string
+)
+
+var policyListCmd = &cobra.Command{
+	Use:   "list [path...]",
+	Short: "Lists the rules in the policy catalog",
+	Long: `Lists every rule the audit checks: the built-in checks, which can be
+selected with audit --enable and --disable, followed by the rules of the
+policies in the given files or directories. Rego deny rules are described by
+their METADATA annotations (title, description, custom.id, custom.severity and
+related_resources); Gatekeeper Constraints and Kyverno rules are listed too.`,
+	Example: `  devguardian policy list
+  devguardian policy list ./policies --output json
+  devguardian policy list --policy-bundle bundle.tar.gz`,
+	Run: func(cmd *cobra.Command, args []string) {
+		paths := args
+		if len(paths) == 0 && len(listBundles) == 0 {
+			paths = []string{opa.DefaultPolicyDir}
+		}
+
+		bundles, err := opa.LoadBundles(listBundles, opa.BundleOptions{PublicKey: listKey, SigningAlg: listAlg})
+		if err != nil {
+			fmt.Printf("❌ Error loading policy bundles: %v\n", err)
+			os.Exit(1)
+		}
+
+		evaluator, err := opa.NewEvaluator(paths, nil, bundles...)
+		if err != nil {
+			fmt.Printf("❌ Error loading policies: %v\n", err)
+			os.Exit(1)
+		}
+		var rules []opa.Rule
+		for _, check := range checks.Default().Checks() {
+			meta := check.Metadata()
+			rules = append(rules, opa.Rule{
+				ID:          check.ID(),
+				Title:       meta.Title,
+				Description: meta.Description,
+				Severity:    meta.Severity,
+				Source:      "builtin",
+			})
+		}
+		rules = append(rules, evaluator.Rules()...)
+
+		kyvernoPolicies, err := kyverno.Load(paths)
+		if err != nil {
+			fmt.Printf("❌ Error loading Kyverno policies: %v\n", err)
+			os.Exit(1)
+		}
+		for _, policy := range kyvernoPolicies {
+			for _, rule := range policy.Rules {
+				rules = append(rules, opa.Rule{
+					ID:          policy.RuleID(rule),
+					Title:       policy.Title,
+					Description: policy.Description,
+					Severity:    policy.Severity,
+					Source:      "kyverno",
+				})
+			}
+		}
+
+		switch listOutput {
+		case "json":
+			if rules == nil {
+				rules = []opa.Rule{}
+			}
+			data, err := json.MarshalIndent(rules, "", "  ")
+			if err != nil {
+				fmt.Printf("❌ Error formatting rules: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+		case "table":
+			printRuleTable(rules)
+		default:
+			fmt.Printf("❌ Unknown output format %q (expected table or json)\n", listOutput)
+			os.Exit(1)
+		}
+	},
+}
+
+// printRuleTable prints the rule catalog as a table
+func printRuleTable(rules []opa.Rule) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSEVERITY\tSOURCE\tTITLE\tLOCATION")
+	for _, rule := range rules {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			orDash(rule.ID), orDash(string(rule.Severity)), rule.Source, orDash(rule.Title), orDash(rule.Location))
+	}
+	w.Flush()
+	fmt.Printf("\n%d rule(s)\n", len(rules))
+}
+
+// orDash returns s, or "-" if it is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func init() {
+	policyCmd.AddCommand(policyListCmd)
+
+	policyListCmd.Flags().StringVarP(&listOutput, "output", "o", "table", "Output format (table, json)")
+	policyListCmd.Flags().StringSliceVar(&listBundles, "policy-bundle", nil, "OPA bundle tarball, directory or http(s) URL (repeatable)")
+	policyListCmd.Flags().StringVar(&listKey, "policy-bundle-key", "", "Public key used to verify policy bundle signatures")
+	policyListCmd.Flags().StringVar(&listAlg, "policy-bundle-alg", opa.DefaultBundleSigningAlg, "Signature algorithm of the policy bundles")
+}
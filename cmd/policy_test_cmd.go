@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/opa"
+)
+
+var (
+	testRun       string
+	testCoverage  bool
+	testThreshold float64
+	testTimeout   time.Duration
+	testVerbose   bool
+)
+
+var policyTestCmd = &cobra.Command{
+	Use:   "test [path...]",
+	Short: "Runs Rego unit tests for policies",
+	Long: `Discovers and runs Rego unit tests (rules prefixed with test_) in the given
+policy files or directories, using OPA test semantics. JSON and YAML files next
+to the policies are loaded as data fixtures. Exits non-zero if any test fails
+or, with --threshold, if coverage is below the threshold.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		paths := args
+		if len(paths) == 0 {
+			paths = []string{opa.DefaultPolicyDir}
+		}
+
+		report, err := opa.RunTests(paths, opa.TestOptions{
+			Run:      testRun,
+			Coverage: testCoverage || testThreshold > 0,
+			Timeout:  testTimeout,
+		})
+		if err != nil {
+			fmt.Printf("❌ Error running policy tests: %v\n", err)
+			os.Exit(1)
+		}
+
+		printTestReport(report)
+
+		if !report.Succeeded() {
+			os.Exit(1)
+		}
+		if testThreshold > 0 && report.Coverage.Coverage < testThreshold {
+			fmt.Printf("❌ Coverage %.2f%% is below the threshold of %.2f%%\n", report.Coverage.Coverage, testThreshold)
+			os.Exit(1)
+		}
+	},
+}
+
+// printTestReport prints the results of a policy test run
+func printTestReport(report *opa.TestReport) {
+	file := ""
+	for _, r := range report.Results {
+		if r.File != file {
+			file = r.File
+			fmt.Printf("\n%s:\n", file)
+		}
+
+		var status string
+		switch {
+		case r.Skipped:
+			status = "⏭️ SKIPPED"
+		case r.Error != nil:
+			status = fmt.Sprintf("💥 ERROR: %v", r.Error)
+		case r.Passed:
+			status = "✅ PASS"
+		default:
+			status = "❌ FAIL"
+		}
+		fmt.Printf("  %s.%s: %s (%s)\n", r.Package, r.Name, status, r.Duration.Round(time.Microsecond))
+
+		if r.Output != "" && (testVerbose || !r.Passed) {
+			fmt.Printf("    %s\n", r.Output)
+		}
+	}
+
+	fmt.Println("\n-------------------------------------")
+	fmt.Printf("PASS: %d  FAIL: %d  ERROR: %d  SKIPPED: %d\n", report.Passed, report.Failed, report.Errored, report.Skipped)
+
+	if report.Coverage != nil {
+		fmt.Printf("\n📊 COVERAGE: %.2f%%\n", report.Coverage.Coverage)
+		files := make([]string, 0, len(report.Coverage.Files))
+		for f := range report.Coverage.Files {
+			files = append(files, f)
+		}
+		sort.Strings(files)
+		for _, f := range files {
+			fc := report.Coverage.Files[f]
+			fmt.Printf("  - %s: %.2f%%", f, fc.Coverage)
+			if testVerbose && len(fc.NotCovered) > 0 {
+				fmt.Printf(" (not covered:")
+				for _, r := range fc.NotCovered {
+					fmt.Printf(" %d-%d", r.Start.Row, r.End.Row)
+				}
+				fmt.Printf(")")
+			}
+			fmt.Println()
+		}
+	}
+}
+
+func init() {
+	policyCmd.AddCommand(policyTestCmd)
+
+	policyTestCmd.Flags().StringVarP(&testRun, "run", "r", "", "Only run tests whose name matches this regular expression")
+	policyTestCmd.Flags().BoolVarP(&testCoverage, "coverage", "c", false, "Report policy coverage")
+	policyTestCmd.Flags().Float64Var(&testThreshold, "threshold", 0, "Fail if coverage is below this percentage (implies --coverage)")
+	policyTestCmd.Flags().DurationVar(&testTimeout, "timeout", 0, "Timeout for each test (default 5s)")
+	policyTestCmd.Flags().BoolVarP(&testVerbose, "verbose", "v", false, "Print test output and uncovered lines")
+}
@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/config"
 )
 
+// cfgFile is the path of the configuration file
+var cfgFile string
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "k8s-devguardian-ai",
@@ -27,9 +32,19 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.k8s-devguardian-ai.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "Config file (default is "+config.DefaultFile+" in the working directory, if present)")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
+
+// loadConfig reads the configuration file, exiting on error
+func loadConfig() *config.Config {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		fmt.Printf("❌ Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	return cfg
+}
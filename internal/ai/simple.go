@@ -25,19 +25,23 @@ func (e *SimpleExplainer) ExplainFindings(findings []auditor.AuditFinding) ([]Fi
 		var explanation, remediation string
 		references := []string{"https://kubernetes.io/docs/concepts/security/"}
 
-		// Provide basic explanations based on the finding reason
+		// Provide basic explanations based on the rule, falling back to the
+		// finding reason for findings of rules without an ID
 		switch {
-		case contains(finding.Reason, "privileged"):
+		case finding.RuleID == "DG-POD-002", finding.RuleID == "DG-POD-004",
+			finding.RuleID == "" && contains(finding.Reason, "privileged"):
 			explanation = "Privileged containers have access to all devices on the host, which can lead to security vulnerabilities if compromised."
 			remediation = "Remove the privileged flag from the container's securityContext or use a more restrictive security context."
 			references = append(references, "https://kubernetes.io/docs/tasks/configure-pod-container/security-context/")
 
-		case contains(finding.Reason, "root"):
+		case finding.RuleID == "DG-POD-001",
+			finding.RuleID == "" && contains(finding.Reason, "root"):
 			explanation = "Running containers as root (uid 0) gives them elevated permissions, which is a security risk."
 			remediation = "Set runAsUser in the container's securityContext to a non-zero value."
 			references = append(references, "https://kubernetes.io/docs/concepts/security/pod-security-standards/")
 
-		case contains(finding.Reason, "hostPath"):
+		case finding.RuleID == "DG-POD-003",
+			finding.RuleID == "" && contains(finding.Reason, "hostPath"):
 			explanation = "hostPath volumes allow pods to access files on the host, which can lead to privilege escalation."
 			remediation = "Avoid using hostPath volumes. Consider using more secure volume types like emptyDir, configMap, or PersistentVolumeClaims."
 			references = append(references, "https://kubernetes.io/docs/concepts/storage/volumes/")
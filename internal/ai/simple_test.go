@@ -78,3 +78,23 @@ func TestSimpleExplainer_ExplainFindings(t *testing.T) {
 		}
 	}
 }
+
+func TestSimpleExplainer_KeysOnRuleID(t *testing.T) {
+	explainer := NewSimpleExplainer()
+
+	// The reason mentions root, but the rule decides the explanation
+	findings := []auditor.AuditFinding{
+		{Resource: "Pod", Name: "web", Reason: "Container 'root-helper' uses hostPath volume 'data'", RuleID: "DG-POD-003"},
+		{Resource: "Pod", Name: "web", Reason: "Container 'app' runs as root user (uid 0)"},
+	}
+	explanations, err := explainer.ExplainFindings(findings)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !contains(explanations[0].Explanation, "hostPath") {
+		t.Errorf("Expected the hostPath explanation for DG-POD-003, got %q", explanations[0].Explanation)
+	}
+	if !contains(explanations[1].Explanation, "root") {
+		t.Errorf("Expected the reason to be used for a finding without a rule ID, got %q", explanations[1].Explanation)
+	}
+}
@@ -2,7 +2,10 @@ package auditor
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"strings"
 	"github.com/open-policy-agent/opa/rego"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -12,49 +15,49 @@ type AuditFinding struct {
 	Namespace string
 	Name string
 	Reason string
-	Severity string
+	Severity Severity
+	RuleID string // ID of the rule that produced the finding, if known
+	Title string // Title of the rule, if known
+	Cluster string // Cluster the resource was scanned in, if known
+	Container string // Container the finding is about, if it is about a single one
+	Fingerprint string // Stable identifier of the finding, see Fingerprint
+	Suppressed bool // Whether the finding is an accepted risk, see the suppression package
+	Justification string // Why the finding is suppressed
+	File string // Manifest file the resource was read from, for offline scans
+	Line int // Line of File the resource starts at
 }
 
-// AuditPodSecurity runs basic checks on pods and containers
-func AuditPodSecurity(pods []corev1.Pod) []AuditFinding {
-	findings := []AuditFinding{}
-	for _, pod := range pods {
-		for _, c := range pod.Spec.Containers {
-			if c.SecurityContext != nil {
-				if c.SecurityContext.RunAsUser != nil && *c.SecurityContext.RunAsUser == 0 {
-					findings = append(findings, AuditFinding{
-						Resource:  "Pod",
-						Namespace: pod.Namespace,
-						Name:      pod.Name,
-						Reason:    fmt.Sprintf("Container '%s' runs as root user (uid 0)", c.Name),
-						Severity:  "High",
-					})
-				}
-				if c.SecurityContext.Privileged != nil && *c.SecurityContext.Privileged {
-					findings = append(findings, AuditFinding{
-						Resource:  "Pod",
-						Namespace: pod.Namespace,
-						Name:      pod.Name,
-						Reason:    fmt.Sprintf("Container '%s' is privileged", c.Name),
-						Severity:  "Critical",
-					})
-				}
-			}
-			// Check for hostPath mounts
-			for _, v := range pod.Spec.Volumes {
-				if v.HostPath != nil {
-					findings = append(findings, AuditFinding{
-						Resource:  "Pod",
-						Namespace: pod.Namespace,
-						Name:      pod.Name,
-						Reason:    fmt.Sprintf("Container '%s' uses hostPath volume '%s'", c.Name, v.Name),
-						Severity:  "Medium",
-					})
-				}
-			}
+// Fingerprint returns a deterministic hash identifying a finding across scans,
+// computed from its rule, cluster, resource kind, namespace, name and
+// container. It does not depend on the wording of the reason, so it survives
+// message changes; findings of the same rule on the same container share a
+// fingerprint. Findings without a rule ID are identified by their reason
+// instead.
+func Fingerprint(f AuditFinding) string {
+	rule := f.RuleID
+	if rule == "" {
+		rule = "reason:" + f.Reason
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{rule, f.Cluster, f.Resource, f.Namespace, f.Name, f.Container}, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// SetFingerprints sets the Fingerprint of every finding
+func SetFingerprints(findings []AuditFinding) {
+	for i := range findings {
+		findings[i].Fingerprint = Fingerprint(findings[i])
+	}
+}
+
+// Unsuppressed returns the findings that are not suppressed
+func Unsuppressed(findings []AuditFinding) []AuditFinding {
+	var active []AuditFinding
+	for _, f := range findings {
+		if !f.Suppressed {
+			active = append(active, f)
 		}
 	}
-	return findings
+	return active
 }
 
 // EvaluateWithOPA evaluates a pod against a given rego policy
@@ -75,12 +78,19 @@ func EvaluateWithOPA(pod corev1.Pod, regoModule string) ([]AuditFinding, error)
 			if arr, ok := exp.Value.([]interface{}); ok {
 				for _, f := range arr {
 					if m, ok := f.(map[string]interface{}); ok {
+						// Violations without a severity are High
+						severity := SeverityHigh
+						if s, ok := m["severity"].(string); ok {
+							if severity, err = ParseSeverity(s); err != nil {
+								return nil, err
+							}
+						}
 						findings = append(findings, AuditFinding{
 							Resource:  "Pod",
 							Namespace: pod.Namespace,
 							Name:      pod.Name,
 							Reason:    fmt.Sprintf("OPA: %v", m["reason"]),
-							Severity:  fmt.Sprintf("%v", m["severity"]),
+							Severity:  severity,
 						})
 					}
 				}
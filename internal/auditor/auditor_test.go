@@ -0,0 +1,52 @@
+package auditor
+
+import "testing"
+
+func TestFingerprint(t *testing.T) {
+	finding := AuditFinding{
+		Resource:  "Pod",
+		Namespace: "default",
+		Name:      "web",
+		Container: "app",
+		Reason:    "Container 'app' is privileged",
+		Severity:  "Critical",
+		RuleID:    "DG-POD-002",
+		Cluster:   "prod",
+	}
+	fingerprint := Fingerprint(finding)
+	if len(fingerprint) != 32 {
+		t.Errorf("Expected a 32 character fingerprint, got %q", fingerprint)
+	}
+
+	// The wording and severity of a finding do not change its fingerprint
+	reworded := finding
+	reworded.Reason = "Container app runs privileged"
+	reworded.Severity = "High"
+	if Fingerprint(reworded) != fingerprint {
+		t.Error("Expected the fingerprint to ignore the reason and severity")
+	}
+
+	for name, change := range map[string]func(*AuditFinding){
+		"rule":      func(f *AuditFinding) { f.RuleID = "DG-POD-001" },
+		"cluster":   func(f *AuditFinding) { f.Cluster = "staging" },
+		"kind":      func(f *AuditFinding) { f.Resource = "Deployment" },
+		"namespace": func(f *AuditFinding) { f.Namespace = "kube-system" },
+		"name":      func(f *AuditFinding) { f.Name = "api" },
+		"container": func(f *AuditFinding) { f.Container = "sidecar" },
+	} {
+		changed := finding
+		change(&changed)
+		if Fingerprint(changed) == fingerprint {
+			t.Errorf("Expected a different fingerprint when the %s changes", name)
+		}
+	}
+
+	// Without a rule ID the reason identifies the finding
+	unattributed := finding
+	unattributed.RuleID = ""
+	other := unattributed
+	other.Reason = "Container 'app' runs as root user (uid 0)"
+	if Fingerprint(unattributed) == Fingerprint(other) {
+		t.Error("Expected findings without a rule ID to be told apart by their reason")
+	}
+}
@@ -0,0 +1,104 @@
+package auditor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is the severity of a finding. Severities are ordered from
+// SeverityInfo to SeverityCritical; the zero value means the severity is not
+// set.
+type Severity string
+
+const (
+	SeverityInfo     Severity = "Info"
+	SeverityLow      Severity = "Low"
+	SeverityMedium   Severity = "Medium"
+	SeverityHigh     Severity = "High"
+	SeverityCritical Severity = "Critical"
+)
+
+// Severities lists every severity, from the lowest to the highest
+var Severities = []Severity{SeverityInfo, SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// ParseSeverity parses a severity case-insensitively, so "high", "HIGH" and
+// "High" are all SeverityHigh
+func ParseSeverity(s string) (Severity, error) {
+	for _, severity := range Severities {
+		if strings.EqualFold(strings.TrimSpace(s), string(severity)) {
+			return severity, nil
+		}
+	}
+	return "", fmt.Errorf("invalid severity %q (expected info, low, medium, high or critical)", s)
+}
+
+// Valid reports whether s is one of the known severities
+func (s Severity) Valid() bool {
+	return s.rank() >= 0
+}
+
+// rank returns the position of s in Severities, or -1 if s is not valid
+func (s Severity) rank() int {
+	for i, severity := range Severities {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
+
+// Compare returns -1, 0 or +1 depending on whether s is lower than, equal to
+// or higher than other. Unset and invalid severities are lower than any valid
+// severity.
+func (s Severity) Compare(other Severity) int {
+	a, b := s.rank(), other.rank()
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// AtLeast reports whether s is as severe as min or more
+func (s Severity) AtLeast(min Severity) bool {
+	return s.Compare(min) >= 0
+}
+
+// String returns the severity, such as "High"
+func (s Severity) String() string {
+	return string(s)
+}
+
+// MarshalText implements encoding.TextMarshaler, so severities are encoded as
+// strings in JSON, including as map keys
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Severities are parsed
+// case-insensitively and an empty string leaves the severity unset.
+func (s *Severity) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*s = ""
+		return nil
+	}
+	severity, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = severity
+	return nil
+}
+
+// FilterBySeverity returns the findings whose severity is at least min
+func FilterBySeverity(findings []AuditFinding, min Severity) []AuditFinding {
+	var kept []AuditFinding
+	for _, f := range findings {
+		if f.Severity.AtLeast(min) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
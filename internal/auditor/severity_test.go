@@ -0,0 +1,71 @@
+package auditor
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseSeverity(t *testing.T) {
+	for input, expected := range map[string]Severity{
+		"info":     SeverityInfo,
+		"Low":      SeverityLow,
+		"MEDIUM":   SeverityMedium,
+		" high ":   SeverityHigh,
+		"critical": SeverityCritical,
+	} {
+		severity, err := ParseSeverity(input)
+		if err != nil || severity != expected {
+			t.Errorf("ParseSeverity(%q) = %q, %v; expected %q", input, severity, err, expected)
+		}
+	}
+	for _, input := range []string{"", "severe", "warning"} {
+		if _, err := ParseSeverity(input); err == nil {
+			t.Errorf("Expected an error for %q", input)
+		}
+	}
+}
+
+func TestSeverity_Compare(t *testing.T) {
+	for i := 1; i < len(Severities); i++ {
+		if Severities[i-1].Compare(Severities[i]) != -1 || Severities[i].Compare(Severities[i-1]) != 1 {
+			t.Errorf("Expected %s to be lower than %s", Severities[i-1], Severities[i])
+		}
+	}
+	if !SeverityHigh.AtLeast(SeverityHigh) || !SeverityCritical.AtLeast(SeverityHigh) || SeverityMedium.AtLeast(SeverityHigh) {
+		t.Error("Unexpected AtLeast result")
+	}
+	if Severity("").AtLeast(SeverityInfo) || Severity("severe").Valid() {
+		t.Error("Expected unset and unknown severities to be lower than Info")
+	}
+}
+
+func TestSeverity_JSON(t *testing.T) {
+	data, err := json.Marshal(map[Severity]int{SeverityHigh: 2})
+	if err != nil || string(data) != `{"High":2}` {
+		t.Fatalf("Unexpected encoding %s, %v", data, err)
+	}
+
+	var finding AuditFinding
+	if err := json.Unmarshal([]byte(`{"Severity":"critical"}`), &finding); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if finding.Severity != SeverityCritical {
+		t.Errorf("Expected the severity to be normalized, got %q", finding.Severity)
+	}
+	if err := json.Unmarshal([]byte(`{"Severity":"severe"}`), &finding); err == nil {
+		t.Error("Expected an error for an invalid severity")
+	}
+}
+
+func TestFilterBySeverity(t *testing.T) {
+	findings := []AuditFinding{
+		{Name: "a", Severity: SeverityLow},
+		{Name: "b", Severity: SeverityHigh},
+		{Name: "c", Severity: SeverityCritical},
+		{Name: "d"},
+	}
+	kept := FilterBySeverity(findings, SeverityHigh)
+	if len(kept) != 2 || kept[0].Name != "b" || kept[1].Name != "c" {
+		t.Errorf("Expected the High and Critical findings, got %+v", kept)
+	}
+}
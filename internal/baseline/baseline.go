@@ -0,0 +1,60 @@
+// Package baseline compares the findings of an audit with those of a previous
+// report, so that only regressions are reported
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/output"
+)
+
+// Load reads the findings of a JSON report. Findings of reports written before
+// fingerprints were recorded are fingerprinted on load.
+func Load(path string) ([]auditor.AuditFinding, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	var report output.AuditResult
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s (expected a JSON report): %w", path, err)
+	}
+	for i := range report.Findings {
+		if report.Findings[i].Fingerprint == "" {
+			report.Findings[i].Fingerprint = auditor.Fingerprint(report.Findings[i])
+		}
+	}
+	return report.Findings, nil
+}
+
+// Write writes findings to path as a JSON report without explanations, which
+// can be read back with Load
+func Write(path string, findings []auditor.AuditFinding) error {
+	if findings == nil {
+		findings = []auditor.AuditFinding{}
+	}
+	data, err := output.NewJSONFormatter().Format(output.AuditResult{
+		Findings: findings,
+		Summary:  output.GenerateSummary(findings),
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+// Compare matches the current findings against the baseline by fingerprint.
+// It returns the current findings that are not in the baseline, and the
+// baseline findings that are no longer found. Findings sharing a fingerprint
+// are matched one for one, so a second occurrence is new, and findings whose
+// severity changed are not new.
+func Compare(baseline, current []auditor.AuditFinding) (added, fixed []auditor.AuditFinding) {
+	diff := output.NewDiff(baseline, current)
+	return diff.New, diff.Resolved
+}
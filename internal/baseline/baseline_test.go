@@ -0,0 +1,53 @@
+package baseline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+)
+
+func TestWriteLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	findings := []auditor.AuditFinding{
+		{Resource: "Pod", Namespace: "default", Name: "web", RuleID: "DG-POD-002", Severity: auditor.SeverityCritical, Fingerprint: "a"},
+	}
+	if err := Write(path, findings); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(loaded) != 1 || loaded[0] != findings[0] {
+		t.Errorf("Expected the findings back, got %+v", loaded)
+	}
+
+	// Reports without fingerprints are fingerprinted on load
+	os.WriteFile(path, []byte(`{"Findings": [{"Resource": "Pod", "Namespace": "default", "Name": "web", "RuleID": "DG-POD-002"}]}`), 0644)
+	if loaded, err = Load(path); err != nil || len(loaded) != 1 || loaded[0].Fingerprint != auditor.Fingerprint(loaded[0]) {
+		t.Errorf("Expected the finding to be fingerprinted, got %+v, %v", loaded, err)
+	}
+
+	os.WriteFile(path, []byte("<html>"), 0644)
+	if _, err := Load(path); err == nil {
+		t.Error("Expected an error for a report that is not JSON")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	finding := func(name, fingerprint string) auditor.AuditFinding {
+		return auditor.AuditFinding{Name: name, Fingerprint: fingerprint}
+	}
+	previous := []auditor.AuditFinding{finding("kept", "a"), finding("fixed", "b"), finding("duplicate", "c")}
+	current := []auditor.AuditFinding{finding("kept", "a"), finding("duplicate", "c"), finding("duplicate", "c"), finding("new", "d")}
+
+	added, fixed := Compare(previous, current)
+	if len(added) != 2 || added[0].Fingerprint != "c" || added[1].Name != "new" {
+		t.Errorf("Expected the second duplicate and the new finding to be added, got %+v", added)
+	}
+	if len(fixed) != 1 || fixed[0].Name != "fixed" {
+		t.Errorf("Expected the missing finding to be fixed, got %+v", fixed)
+	}
+}
@@ -0,0 +1,96 @@
+// Package config reads the devguardian configuration file
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/opa"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultFile is the configuration file read from the working directory when
+// no file is given
+const DefaultFile = ".devguardian.yaml"
+
+// Config is the devguardian configuration file:
+//
+//	params:              # data.params for every policy
+//	  registries: [registry.example.com/]
+//	policies:
+//	  ACME-IMG-001:      # rule ID
+//	    params:          # merged over data.params for this rule only
+//	      registries: [registry.example.com/, quay.io/acme/]
+//	suppressions:
+//	  annotations: false # ignore the devguardian.io/ignore annotations
+type Config struct {
+	// Params are made available to every policy as data.params
+	Params map[string]interface{} `json:"params,omitempty"`
+	// Policies configures individual policies by rule ID
+	Policies map[string]Policy `json:"policies,omitempty"`
+	// Suppressions configures how findings are suppressed
+	Suppressions Suppressions `json:"suppressions,omitempty"`
+}
+
+// Suppressions configures how findings are suppressed
+type Suppressions struct {
+	// Annotations enables suppressing findings with the devguardian.io/ignore
+	// annotations on resources and namespaces. It is enabled when unset.
+	Annotations *bool `json:"annotations,omitempty"`
+}
+
+// AnnotationsEnabled reports whether findings may be suppressed with
+// annotations
+func (s Suppressions) AnnotationsEnabled() bool {
+	return s.Annotations == nil || *s.Annotations
+}
+
+// Policy configures a single policy
+type Policy struct {
+	// Params are merged over data.params when evaluating the policy
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// Load reads the configuration file at path. If path is empty DefaultFile is
+// read if it exists, and an empty configuration is returned otherwise.
+// Unknown fields are rejected so that typos are not silently ignored.
+func Load(path string) (*Config, error) {
+	if path == "" {
+		if _, err := os.Stat(DefaultFile); err != nil {
+			return &Config{}, nil
+		}
+		path = DefaultFile
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	var config Config
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// PolicyParams returns the parameters passed to the policies. data.params is
+// the params of the config, overridden by the data files in order, and the
+// params of each configured policy are merged over it for that policy.
+func (c *Config) PolicyParams(dataFiles []string) (opa.Params, error) {
+	fileParams, err := opa.LoadParams(dataFiles)
+	if err != nil {
+		return opa.Params{}, err
+	}
+
+	params := opa.Params{Global: opa.MergeParams(c.Params, fileParams)}
+	for id, policy := range c.Policies {
+		if len(policy.Params) == 0 {
+			continue
+		}
+		if params.Rules == nil {
+			params.Rules = make(map[string]map[string]interface{})
+		}
+		params.Rules[id] = policy.Params
+	}
+	return params, nil
+}
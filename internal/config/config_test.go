@@ -0,0 +1,99 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "devguardian.yaml")
+	os.WriteFile(path, []byte(`params:
+  registries: [registry.example.com/]
+policies:
+  ACME-IMG-001:
+    params:
+      registries: [quay.io/acme/]
+`), 0644)
+
+	config, err := Load(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := config.Policies["ACME-IMG-001"].Params["registries"]; !reflect.DeepEqual(got, []interface{}{"quay.io/acme/"}) {
+		t.Errorf("Expected the policy's registries, got %v", got)
+	}
+	if !config.Suppressions.AnnotationsEnabled() {
+		t.Error("Expected annotation suppressions to be enabled by default")
+	}
+
+	os.WriteFile(path, []byte("suppressions:\n  annotations: false\n"), 0644)
+	if config, err = Load(path); err != nil || config.Suppressions.AnnotationsEnabled() {
+		t.Errorf("Expected annotation suppressions to be disabled, got %v", err)
+	}
+
+	os.WriteFile(path, []byte("parms:\n  registries: []\n"), 0644)
+	if _, err := Load(path); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+	if _, err := Load(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestLoad_Default(t *testing.T) {
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	os.Chdir(t.TempDir())
+
+	config, err := Load("")
+	if err != nil {
+		t.Fatalf("Expected no error without a config file, got %v", err)
+	}
+	if len(config.Params) != 0 || len(config.Policies) != 0 {
+		t.Errorf("Expected an empty config, got %+v", config)
+	}
+
+	os.WriteFile(DefaultFile, []byte("params:\n  max_replicas: 3\n"), 0644)
+	config, err = Load("")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if config.Params["max_replicas"] != float64(3) {
+		t.Errorf("Expected the default config file to be read, got %+v", config)
+	}
+}
+
+func TestConfig_PolicyParams(t *testing.T) {
+	dataFile := filepath.Join(t.TempDir(), "prod.yaml")
+	os.WriteFile(dataFile, []byte("registries: [prod.example.com/]\n"), 0644)
+
+	config := &Config{
+		Params: map[string]interface{}{
+			"registries":   []interface{}{"registry.example.com/"},
+			"max_replicas": float64(3),
+		},
+		Policies: map[string]Policy{
+			"ACME-IMG-001":      {Params: map[string]interface{}{"registries": []interface{}{"quay.io/acme/"}}},
+			"ACME-REPLICAS-001": {},
+		},
+	}
+	params, err := config.PolicyParams([]string{dataFile})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Data files override the params of the config
+	wantGlobal := map[string]interface{}{
+		"registries":   []interface{}{"prod.example.com/"},
+		"max_replicas": float64(3),
+	}
+	if !reflect.DeepEqual(params.Global, wantGlobal) {
+		t.Errorf("Expected global params %v, got %v", wantGlobal, params.Global)
+	}
+	if len(params.Rules) != 1 || params.Rules["ACME-IMG-001"] == nil {
+		t.Errorf("Expected params for ACME-IMG-001 only, got %v", params.Rules)
+	}
+}
@@ -0,0 +1,294 @@
+package kyverno
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/wildcard"
+)
+
+// Conditions is a set of Kyverno conditions, satisfied when every condition
+// in All holds and, if Any is non-empty, at least one condition in Any holds
+type Conditions struct {
+	Any []Condition
+	All []Condition
+}
+
+// Condition compares a key, usually a variable, with a value using an operator
+type Condition struct {
+	Key      interface{} `json:"key"`
+	Operator string      `json:"operator"`
+	Value    interface{} `json:"value"`
+}
+
+// parseConditions parses conditions in either the any/all form or the legacy
+// list form, where every condition must hold
+func parseConditions(raw interface{}) (*Conditions, error) {
+	switch c := raw.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		all, err := parseConditionList(c)
+		if err != nil {
+			return nil, err
+		}
+		return &Conditions{All: all}, nil
+	case map[string]interface{}:
+		anyList, _ := c["any"].([]interface{})
+		allList, _ := c["all"].([]interface{})
+		conditions := &Conditions{}
+		var err error
+		if conditions.Any, err = parseConditionList(anyList); err != nil {
+			return nil, err
+		}
+		if conditions.All, err = parseConditionList(allList); err != nil {
+			return nil, err
+		}
+		return conditions, nil
+	}
+	return nil, fmt.Errorf("expected a list or an any/all block, got %T", raw)
+}
+
+// parseConditionList parses a list of conditions
+func parseConditionList(raw []interface{}) ([]Condition, error) {
+	var conditions []Condition
+	for _, r := range raw {
+		m, ok := r.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected a condition, got %T", r)
+		}
+		operator, _ := m["operator"].(string)
+		if operator == "" {
+			return nil, fmt.Errorf("condition is missing an operator")
+		}
+		conditions = append(conditions, Condition{Key: m["key"], Operator: operator, Value: m["value"]})
+	}
+	return conditions, nil
+}
+
+// evaluate reports whether the conditions hold for the context
+func (c *Conditions) evaluate(ctx *context) (bool, error) {
+	for _, condition := range c.All {
+		ok, err := condition.evaluate(ctx)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	if len(c.Any) == 0 {
+		return true, nil
+	}
+	for _, condition := range c.Any {
+		ok, err := condition.evaluate(ctx)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// evaluate reports whether a single condition holds for the context
+func (c Condition) evaluate(ctx *context) (bool, error) {
+	key, err := ctx.substitute(c.Key)
+	if err != nil {
+		return false, err
+	}
+	value, err := ctx.substitute(c.Value)
+	if err != nil {
+		return false, err
+	}
+
+	switch strings.ToLower(c.Operator) {
+	case "equals", "equal":
+		return equals(key, value), nil
+	case "notequals", "notequal":
+		return !equals(key, value), nil
+	case "in", "anyin":
+		return anyIn(key, value), nil
+	case "allin":
+		return allIn(key, value), nil
+	case "notin", "allnotin":
+		return !anyIn(key, value), nil
+	case "anynotin":
+		return !allIn(key, value), nil
+	case "greaterthan":
+		return compareQuantity(key, stringValue(value), ">"), nil
+	case "greaterthanorequals":
+		return compareQuantity(key, stringValue(value), ">="), nil
+	case "lessthan":
+		return compareQuantity(key, stringValue(value), "<"), nil
+	case "lessthanorequals":
+		return compareQuantity(key, stringValue(value), "<="), nil
+	}
+	return false, fmt.Errorf("unsupported condition operator %q", c.Operator)
+}
+
+// equals compares a key with a value. String values may contain wildcards,
+// and numbers and quantities are compared numerically.
+func equals(key, value interface{}) bool {
+	switch key.(type) {
+	case map[string]interface{}, []interface{}:
+		return reflect.DeepEqual(key, value)
+	}
+	if s, ok := value.(string); ok && key != nil {
+		k := stringValue(key)
+		if kq, ok := parseQuantity(k); ok {
+			if vq, ok := parseQuantity(s); ok {
+				return kq.Cmp(vq) == 0
+			}
+		}
+		return wildcard.Match(s, k)
+	}
+	return stringValue(key) == stringValue(value)
+}
+
+// anyIn reports whether any element of key is in the value list
+func anyIn(key, value interface{}) bool {
+	for _, k := range asList(key) {
+		for _, v := range asList(value) {
+			if equals(k, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// allIn reports whether every element of key is in the value list
+func allIn(key, value interface{}) bool {
+	keys := asList(key)
+	if len(keys) == 0 {
+		return false
+	}
+	for _, k := range keys {
+		if !anyIn(k, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// asList wraps a scalar in a list
+func asList(v interface{}) []interface{} {
+	if list, ok := v.([]interface{}); ok {
+		return list
+	}
+	if v == nil {
+		return nil
+	}
+	return []interface{}{v}
+}
+
+// variablePattern matches a {{ ... }} variable reference
+var variablePattern = regexp.MustCompile(`\{\{\s*(.*?)\s*\}\}`)
+
+// context holds the variables available to a rule: the admission request
+// built from the scanned object, as in a background scan
+type context struct {
+	variables map[string]interface{}
+}
+
+// newContext creates the variable context for obj
+func newContext(obj map[string]interface{}) *context {
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	return &context{variables: map[string]interface{}{
+		"request": map[string]interface{}{
+			"operation": "CREATE",
+			"object":    obj,
+			"namespace": metadata["namespace"],
+			"name":      metadata["name"],
+		},
+	}}
+}
+
+// substitute replaces the variables in every string within v. A string that
+// consists of a single variable is replaced by the variable's value, keeping
+// its type; variables embedded in a longer string are formatted into it.
+func (c *context) substitute(v interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case string:
+		if m := variablePattern.FindStringSubmatch(t); m != nil && m[0] == strings.TrimSpace(t) {
+			return c.lookup(m[1])
+		}
+		return c.substituteString(t)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			s, err := c.substitute(val)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = s
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, val := range t {
+			s, err := c.substitute(val)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = s
+		}
+		return out, nil
+	}
+	return v, nil
+}
+
+// substituteString formats the variables in s into the string
+func (c *context) substituteString(s string) (string, error) {
+	var lookupErr error
+	result := variablePattern.ReplaceAllStringFunc(s, func(ref string) string {
+		expr := variablePattern.FindStringSubmatch(ref)[1]
+		value, err := c.lookup(expr)
+		if err != nil {
+			lookupErr = err
+			return ref
+		}
+		return stringValue(value)
+	})
+	return result, lookupErr
+}
+
+// lookup resolves a variable expression. Only plain paths such as
+// request.object.metadata.labels."app.kubernetes.io/name" are supported;
+// JMESPath functions, filters and projections are not. Paths that do not
+// exist resolve to nil.
+func (c *context) lookup(expr string) (interface{}, error) {
+	if strings.ContainsAny(expr, "|()[]?@&`") {
+		return nil, fmt.Errorf("unsupported variable expression %q: only plain paths are supported", expr)
+	}
+
+	var current interface{} = c.variables
+	for _, part := range splitPath(expr) {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		current = m[part]
+	}
+	return current, nil
+}
+
+// splitPath splits a dotted path, honouring double-quoted segments
+func splitPath(expr string) []string {
+	var parts []string
+	var current strings.Builder
+	quoted := false
+	for _, r := range expr {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == '.' && !quoted:
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	return append(parts, current.String())
+}
@@ -0,0 +1,458 @@
+package kyverno
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/labelselector"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/manifest"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/wildcard"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// kyvernoGroup is the API group of Kyverno policies
+	kyvernoGroup = "kyverno.io"
+	// severityAnnotation is the standard Kyverno annotation for policy severity
+	severityAnnotation = "policies.kyverno.io/severity"
+	// titleAnnotation is the standard Kyverno annotation for the policy title
+	titleAnnotation = "policies.kyverno.io/title"
+	// descriptionAnnotation is the standard Kyverno annotation for the policy description
+	descriptionAnnotation = "policies.kyverno.io/description"
+	// defaultSeverity is used for policies without a severity annotation
+	defaultSeverity = auditor.SeverityHigh
+)
+
+// Policy is a Kyverno ClusterPolicy or Policy with its validate rules
+type Policy struct {
+	Kind        string // ClusterPolicy or Policy
+	Name        string
+	Namespace   string // Namespace of a Policy; empty for a ClusterPolicy
+	Severity    auditor.Severity
+	Title       string
+	Description string
+	Rules       []Rule
+}
+
+// Rule is a single validate rule of a Kyverno policy
+type Rule struct {
+	Name          string         `json:"name"`
+	Match         MatchResources `json:"match"`
+	Exclude       MatchResources `json:"exclude"`
+	Preconditions *Conditions    `json:"-"`
+	Validate      *Validation    `json:"validate"`
+}
+
+// Validation is the validate block of a rule. Only pattern, anyPattern and
+// deny are supported; rules using other validation types are skipped.
+type Validation struct {
+	Message    string        `json:"message"`
+	Pattern    interface{}   `json:"pattern"`
+	AnyPattern []interface{} `json:"anyPattern"`
+	Deny       *Deny         `json:"deny"`
+}
+
+// Deny holds the conditions of a deny rule
+type Deny struct {
+	Conditions *Conditions `json:"-"`
+}
+
+// MatchResources selects the resources a rule applies to, either through the
+// any/all lists of resource filters or the legacy resources block
+type MatchResources struct {
+	Any       []ResourceFilter     `json:"any"`
+	All       []ResourceFilter     `json:"all"`
+	Resources *ResourceDescription `json:"resources"`
+}
+
+// ResourceFilter is a single entry of a match/exclude any or all list
+type ResourceFilter struct {
+	Resources ResourceDescription `json:"resources"`
+}
+
+// ResourceDescription describes the resources selected by a filter
+type ResourceDescription struct {
+	Kinds             []string              `json:"kinds"`
+	Names             []string              `json:"names"`
+	Name              string                `json:"name"`
+	Namespaces        []string              `json:"namespaces"`
+	Selector          *metav1.LabelSelector `json:"selector"`
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector"`
+
+	// The selectors converted when the policy is loaded; nil when unset
+	CompiledSelector          labels.Selector `json:"-"`
+	CompiledNamespaceSelector labels.Selector `json:"-"`
+}
+
+// Load reads the Kyverno ClusterPolicies and Policies found in the YAML files
+// under paths. Other documents, including YAML documents that are not objects
+// such as test fixtures, are ignored.
+func Load(paths []string) ([]*Policy, error) {
+	var manifestPaths []string
+	for _, path := range paths {
+		if !strings.HasSuffix(path, ".rego") {
+			manifestPaths = append(manifestPaths, path)
+		}
+	}
+	objects, err := manifest.LoadObjects(manifestPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	var policies []*Policy
+	for _, obj := range objects {
+		apiVersion, _ := obj.Object["apiVersion"].(string)
+		if strings.SplitN(apiVersion, "/", 2)[0] != kyvernoGroup {
+			continue
+		}
+		if obj.Kind() != "ClusterPolicy" && obj.Kind() != "Policy" {
+			continue
+		}
+		policy, err := parsePolicy(obj)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// parsePolicy converts an unstructured Kyverno policy
+func parsePolicy(obj manifest.Object) (*Policy, error) {
+	policy := &Policy{
+		Kind:     obj.Kind(),
+		Name:     obj.Name(),
+		Severity: defaultSeverity,
+	}
+	if obj.Kind() == "Policy" {
+		policy.Namespace = obj.Namespace()
+		if policy.Namespace == "" {
+			policy.Namespace = "default"
+		}
+	}
+
+	metadata, _ := obj.Object["metadata"].(map[string]interface{})
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+	if severity, ok := annotations[severityAnnotation].(string); ok && severity != "" {
+		var err error
+		if policy.Severity, err = auditor.ParseSeverity(severity); err != nil {
+			return nil, fmt.Errorf("Kyverno policy %s: %w", policy.Name, err)
+		}
+	}
+	policy.Title, _ = annotations[titleAnnotation].(string)
+	description, _ := annotations[descriptionAnnotation].(string)
+	policy.Description = strings.TrimSpace(description)
+
+	spec, _ := obj.Object["spec"].(map[string]interface{})
+	rules, _ := spec["rules"].([]interface{})
+	for _, r := range rules {
+		raw, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var rule Rule
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &rule); err != nil {
+			return nil, fmt.Errorf("invalid rule in %s %s (%s): %w", obj.Kind(), obj.Name(), obj.File, err)
+		}
+		if rule.Validate == nil {
+			// Mutate, generate and verifyImages rules are not audits
+			continue
+		}
+
+		if err := rule.Match.compileSelectors(); err != nil {
+			return nil, fmt.Errorf("invalid match in rule %s of %s: %w", rule.Name, obj.Name(), err)
+		}
+		if err := rule.Exclude.compileSelectors(); err != nil {
+			return nil, fmt.Errorf("invalid exclude in rule %s of %s: %w", rule.Name, obj.Name(), err)
+		}
+		var err error
+		if rule.Preconditions, err = parseConditions(raw["preconditions"]); err != nil {
+			return nil, fmt.Errorf("invalid preconditions in rule %s of %s: %w", rule.Name, obj.Name(), err)
+		}
+		validate, _ := raw["validate"].(map[string]interface{})
+		if deny, ok := validate["deny"].(map[string]interface{}); ok {
+			conditions, err := parseConditions(deny["conditions"])
+			if err != nil {
+				return nil, fmt.Errorf("invalid deny conditions in rule %s of %s: %w", rule.Name, obj.Name(), err)
+			}
+			rule.Validate.Deny = &Deny{Conditions: conditions}
+		}
+		policy.Rules = append(policy.Rules, rule)
+	}
+	return policy, nil
+}
+
+// Evaluate validates obj against every rule of the policy that matches it and
+// returns a finding for each failed rule. namespaceLabels are the labels of
+// the object's namespace, used for namespaceSelector matching.
+func (p *Policy) Evaluate(obj map[string]interface{}, namespaceLabels map[string]interface{}) ([]auditor.AuditFinding, error) {
+	res := newResourceInfo(obj, namespaceLabels)
+	if p.Namespace != "" && res.namespace != p.Namespace {
+		return nil, nil
+	}
+
+	// Findings on a Namespace are reported under the namespace itself, as
+	// those of the built-in checks are
+	namespace := res.namespace
+	if res.kind == "Namespace" {
+		namespace = res.name
+	}
+
+	var findings []auditor.AuditFinding
+	for _, rule := range p.Rules {
+		if !rule.Match.matches(res) || rule.Exclude.excludes(res) {
+			continue
+		}
+
+		ctx := newContext(obj)
+		if rule.Preconditions != nil {
+			ok, err := rule.Preconditions.evaluate(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s/%s: %w", p.Name, rule.Name, err)
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		failure, err := rule.Validate.validate(ctx, obj)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s/%s: %w", p.Name, rule.Name, err)
+		}
+		if failure == "" {
+			continue
+		}
+
+		findings = append(findings, auditor.AuditFinding{
+			Resource:  res.kind,
+			Namespace: namespace,
+			Name:      res.name,
+			Reason:    fmt.Sprintf("[%s/%s] %s", p.Name, rule.Name, failure),
+			Severity:  p.Severity,
+			RuleID:    p.RuleID(rule),
+			Title:     p.Title,
+		})
+	}
+	return findings, nil
+}
+
+// RuleID returns the ID of a rule of the policy, policy/rule
+func (p *Policy) RuleID(rule Rule) string {
+	return fmt.Sprintf("%s/%s", p.Name, rule.Name)
+}
+
+// validate runs the validation against obj and returns a description of the
+// failure, or an empty string if the object is valid
+func (v *Validation) validate(ctx *context, obj map[string]interface{}) (string, error) {
+	message, err := ctx.substituteString(v.Message)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case v.Pattern != nil:
+		pattern, err := ctx.substitute(v.Pattern)
+		if err != nil {
+			return "", err
+		}
+		if path, ok := validatePattern(obj, pattern); !ok {
+			return failureMessage(message, fmt.Sprintf("failed at path %s", path)), nil
+		}
+
+	case len(v.AnyPattern) > 0:
+		var paths []string
+		for _, p := range v.AnyPattern {
+			pattern, err := ctx.substitute(p)
+			if err != nil {
+				return "", err
+			}
+			path, ok := validatePattern(obj, pattern)
+			if ok {
+				return "", nil
+			}
+			paths = append(paths, path)
+		}
+		return failureMessage(message, fmt.Sprintf("no pattern matched (failed at %s)", strings.Join(paths, ", "))), nil
+
+	case v.Deny != nil:
+		if v.Deny.Conditions != nil {
+			denied, err := v.Deny.Conditions.evaluate(ctx)
+			if err != nil || !denied {
+				return "", err
+			}
+		}
+		if message == "" {
+			return "denied by rule", nil
+		}
+		return message, nil
+	}
+	return "", nil
+}
+
+// failureMessage combines the rule's message with the failure detail
+func failureMessage(message, detail string) string {
+	if message == "" {
+		return detail
+	}
+	return fmt.Sprintf("%s (%s)", message, detail)
+}
+
+// resourceInfo is the identifying information of an object used for matching
+type resourceInfo struct {
+	apiVersion      string
+	kind            string
+	name            string
+	namespace       string
+	labels          map[string]interface{}
+	namespaceLabels map[string]interface{}
+}
+
+// newResourceInfo extracts the identifying information of obj
+func newResourceInfo(obj map[string]interface{}, namespaceLabels map[string]interface{}) resourceInfo {
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	r := resourceInfo{namespaceLabels: namespaceLabels}
+	r.apiVersion, _ = obj["apiVersion"].(string)
+	r.kind, _ = obj["kind"].(string)
+	r.name, _ = metadata["name"].(string)
+	r.namespace, _ = metadata["namespace"].(string)
+	r.labels, _ = metadata["labels"].(map[string]interface{})
+	return r
+}
+
+// matches reports whether the resource is selected by the match block
+func (m MatchResources) matches(r resourceInfo) bool {
+	if m.Resources != nil {
+		return m.Resources.matches(r)
+	}
+	for _, f := range m.All {
+		if !f.Resources.matches(r) {
+			return false
+		}
+	}
+	if len(m.Any) == 0 {
+		return len(m.All) > 0
+	}
+	for _, f := range m.Any {
+		if f.Resources.matches(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// compileSelectors converts the label selectors of the block's filters
+func (m *MatchResources) compileSelectors() error {
+	if m.Resources != nil {
+		if err := m.Resources.compileSelectors(); err != nil {
+			return err
+		}
+	}
+	for _, filters := range [][]ResourceFilter{m.Any, m.All} {
+		for i := range filters {
+			if err := filters[i].Resources.compileSelectors(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// compileSelectors converts the label selectors of the description
+func (d *ResourceDescription) compileSelectors() error {
+	var err error
+	if d.CompiledSelector, err = labelselector.Compile(d.Selector); err != nil {
+		return fmt.Errorf("selector: %w", err)
+	}
+	if d.CompiledNamespaceSelector, err = labelselector.Compile(d.NamespaceSelector); err != nil {
+		return fmt.Errorf("namespaceSelector: %w", err)
+	}
+	return nil
+}
+
+// excludes reports whether the resource is selected by the exclude block. An
+// empty exclude block excludes nothing.
+func (m MatchResources) excludes(r resourceInfo) bool {
+	if m.Resources == nil && len(m.Any) == 0 && len(m.All) == 0 {
+		return false
+	}
+	return m.matches(r)
+}
+
+// matches reports whether the resource satisfies every set field of the
+// description
+func (d ResourceDescription) matches(r resourceInfo) bool {
+	if len(d.Kinds) > 0 && !matchesAnyKind(d.Kinds, r.apiVersion, r.kind) {
+		return false
+	}
+	names := d.Names
+	if d.Name != "" {
+		names = append(names, d.Name)
+	}
+	if len(names) > 0 && !matchesAnyWildcard(names, r.name) {
+		return false
+	}
+	if len(d.Namespaces) > 0 {
+		namespace := r.namespace
+		if r.kind == "Namespace" {
+			namespace = r.name
+		}
+		if !matchesAnyWildcard(d.Namespaces, namespace) {
+			return false
+		}
+	}
+	if !labelselector.Matches(d.CompiledSelector, r.labels) {
+		return false
+	}
+	// The namespaceSelector does not apply to cluster-scoped objects other
+	// than Namespaces, which are matched by their own labels
+	if d.CompiledNamespaceSelector != nil && (r.namespace != "" || r.kind == "Namespace") {
+		namespaceLabels := r.namespaceLabels
+		if r.kind == "Namespace" {
+			namespaceLabels = r.labels
+		}
+		if !labelselector.Matches(d.CompiledNamespaceSelector, namespaceLabels) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesAnyKind matches an object against Kyverno kind selectors, which may
+// be "Kind", "version/Kind" or "group/version/Kind", each part allowing "*"
+func matchesAnyKind(kinds []string, apiVersion, kind string) bool {
+	for _, k := range kinds {
+		parts := strings.Split(k, "/")
+		// Subresources (e.g. Pod/exec) are never scanned
+		if len(parts) == 2 && parts[0] == kind {
+			continue
+		}
+		if !wildcard.Match(parts[len(parts)-1], kind) {
+			continue
+		}
+		if len(parts) > 1 && !wildcard.Match(strings.Join(parts[:len(parts)-1], "/"), apiVersion) &&
+			!(len(parts) == 2 && wildcard.Match(parts[0], versionOf(apiVersion))) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// versionOf returns the version part of an apiVersion
+func versionOf(apiVersion string) string {
+	parts := strings.Split(apiVersion, "/")
+	return parts[len(parts)-1]
+}
+
+// matchesAnyWildcard reports whether value matches any of the patterns
+func matchesAnyWildcard(patterns []string, value string) bool {
+	for _, p := range patterns {
+		if wildcard.Match(p, value) {
+			return true
+		}
+	}
+	return false
+}
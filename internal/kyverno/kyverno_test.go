@@ -0,0 +1,399 @@
+package kyverno
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const policies = `apiVersion: kyverno.io/v1
+kind: ClusterPolicy
+metadata:
+  name: disallow-privileged-containers
+  annotations:
+    policies.kyverno.io/severity: medium
+spec:
+  validationFailureAction: Audit
+  rules:
+    - name: privileged-containers
+      match:
+        any:
+        - resources:
+            kinds:
+              - Pod
+      exclude:
+        any:
+        - resources:
+            namespaces:
+              - kube-*
+      validate:
+        message: Privileged mode is disallowed.
+        pattern:
+          spec:
+            =(initContainers):
+              - =(securityContext):
+                  =(privileged): "false"
+            containers:
+              - =(securityContext):
+                  =(privileged): "false"
+    - name: require-name-label
+      match:
+        any:
+        - resources:
+            kinds:
+              - v1/Pod
+            selector:
+              matchLabels:
+                tier: frontend
+      validate:
+        pattern:
+          metadata:
+            labels:
+              app.kubernetes.io/name: "?*"
+---
+apiVersion: kyverno.io/v1
+kind: ClusterPolicy
+metadata:
+  name: restrict-service-types
+spec:
+  rules:
+    - name: no-external-services
+      match:
+        resources:
+          kinds:
+            - Service
+      preconditions:
+        all:
+        - key: "{{ request.object.metadata.labels.\"example.com/public\" }}"
+          operator: NotEquals
+          value: "true"
+      validate:
+        message: "Service type {{ request.object.spec.type }} is not allowed."
+        deny:
+          conditions:
+            any:
+            - key: "{{ request.object.spec.type }}"
+              operator: AnyIn
+              value: ["NodePort", "LoadBalancer"]
+---
+apiVersion: kyverno.io/v1
+kind: Policy
+metadata:
+  name: nginx-images
+  namespace: web
+spec:
+  rules:
+    - name: pinned-nginx
+      match:
+        any:
+        - resources:
+            kinds: ["Pod"]
+      validate:
+        message: nginx containers must use a pinned image and no hostPath volumes
+        pattern:
+          spec:
+            containers:
+              - (name): "nginx*"
+                image: "!*:latest"
+            X(hostPID): "true"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: not-a-policy
+`
+
+func pod(namespace string, labels map[string]interface{}, containers ...map[string]interface{}) map[string]interface{} {
+	list := make([]interface{}, len(containers))
+	for i, c := range containers {
+		list[i] = c
+	}
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": namespace, "labels": labels},
+		"spec":       map[string]interface{}{"containers": list},
+	}
+}
+
+func TestPolicy_Evaluate(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "policies.yaml"), []byte(policies), 0644)
+
+	loaded, err := Load([]string{dir})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(loaded) != 3 {
+		t.Fatalf("Expected 3 policies, got %d", len(loaded))
+	}
+
+	privileged := map[string]interface{}{"name": "app", "securityContext": map[string]interface{}{"privileged": true}}
+	unprivileged := map[string]interface{}{"name": "app", "securityContext": map[string]interface{}{"privileged": false}}
+	plain := map[string]interface{}{"name": "app"}
+
+	tests := []struct {
+		name    string
+		obj     map[string]interface{}
+		reasons []string
+	}{
+		{
+			name:    "privileged container",
+			obj:     pod("default", nil, plain, privileged),
+			reasons: []string{"[disallow-privileged-containers/privileged-containers] Privileged mode is disallowed. (failed at path /spec/containers/1/securityContext/privileged/)"},
+		},
+		{
+			name: "unprivileged and unset containers",
+			obj:  pod("default", nil, plain, unprivileged),
+		},
+		{
+			name: "excluded namespace",
+			obj:  pod("kube-system", nil, privileged),
+		},
+		{
+			name:    "selected by label without name label",
+			obj:     pod("default", map[string]interface{}{"tier": "frontend"}, plain),
+			reasons: []string{"[disallow-privileged-containers/require-name-label] failed at path /metadata/labels/app.kubernetes.io/name/"},
+		},
+		{
+			name: "selected by label with name label",
+			obj:  pod("default", map[string]interface{}{"tier": "frontend", "app.kubernetes.io/name": "web"}, plain),
+		},
+		{
+			name:    "latest nginx image",
+			obj:     pod("web", nil, map[string]interface{}{"name": "nginx", "image": "nginx:latest"}),
+			reasons: []string{"[nginx-images/pinned-nginx] nginx containers must use a pinned image and no hostPath volumes (failed at path /spec/containers/0/image/)"},
+		},
+		{
+			name: "latest image of another container",
+			obj:  pod("web", nil, map[string]interface{}{"name": "redis", "image": "redis:latest"}),
+		},
+		{
+			name: "namespaced policy in another namespace",
+			obj:  pod("default", nil, map[string]interface{}{"name": "nginx", "image": "nginx:latest"}),
+		},
+		{
+			name: "node port service",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Service",
+				"metadata":   map[string]interface{}{"name": "web", "namespace": "default"},
+				"spec":       map[string]interface{}{"type": "NodePort"},
+			},
+			reasons: []string{"[restrict-service-types/no-external-services] Service type NodePort is not allowed."},
+		},
+		{
+			name: "public node port service",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Service",
+				"metadata": map[string]interface{}{
+					"name":      "web",
+					"namespace": "default",
+					"labels":    map[string]interface{}{"example.com/public": "true"},
+				},
+				"spec": map[string]interface{}{"type": "NodePort"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		var reasons []string
+		for _, policy := range loaded {
+			findings, err := policy.Evaluate(tt.obj, nil)
+			if err != nil {
+				t.Fatalf("%s: expected no error, got %v", tt.name, err)
+			}
+			for _, f := range findings {
+				reasons = append(reasons, f.Reason)
+				if policy.Name == "disallow-privileged-containers" && f.Severity != "Medium" {
+					t.Errorf("%s: expected severity Medium, got %s", tt.name, f.Severity)
+				}
+			}
+		}
+		if strings.Join(reasons, "\n") != strings.Join(tt.reasons, "\n") {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.reasons, reasons)
+		}
+	}
+}
+
+func TestMatchScalar(t *testing.T) {
+	tests := []struct {
+		value   interface{}
+		pattern interface{}
+		match   bool
+	}{
+		{"nginx:1.25", "nginx:*", true},
+		{"redis:7", "nginx:*", false},
+		{"", "?*", false},
+		{"x", "?*", true},
+		{nil, "*", false},
+		{"nginx:latest", "!*:latest", false},
+		{"IfNotPresent", "Always | IfNotPresent", true},
+		{int64(3), ">2", true},
+		{int64(3), "<=2", false},
+		{"512Mi", "<1Gi", true},
+		{"2Gi", "<1Gi", false},
+		{int64(5), "1-10", true},
+		{int64(5), "1!-10", false},
+		{true, false, false},
+		{false, "false", true},
+		{int64(8080), float64(8080), true},
+	}
+	for _, tt := range tests {
+		if got := matchScalar(tt.value, tt.pattern); got != tt.match {
+			t.Errorf("matchScalar(%v, %v) = %v, expected %v", tt.value, tt.pattern, got, tt.match)
+		}
+	}
+}
+
+func TestPattern_GlobalAnchor(t *testing.T) {
+	pattern := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{
+					"<(image)": "*:latest",
+					"name":     "pinned-*",
+				},
+			},
+		},
+	}
+	obj := pod("default", nil, map[string]interface{}{"name": "app", "image": "app:1.0"})
+	if _, ok := validatePattern(obj, pattern); !ok {
+		t.Errorf("Expected the rule to be skipped when the global anchor does not match")
+	}
+
+	obj = pod("default", nil, map[string]interface{}{"name": "app", "image": "app:latest"})
+	if path, ok := validatePattern(obj, pattern); ok || path != "/spec/containers/0/name/" {
+		t.Errorf("Expected a failure at /spec/containers/0/name/, got %q (ok=%v)", path, ok)
+	}
+}
+
+func TestConditions_UnsupportedExpression(t *testing.T) {
+	conditions := &Conditions{All: []Condition{{
+		Key:      "{{ request.object.spec.containers[].image }}",
+		Operator: "Equals",
+		Value:    "nginx",
+	}}}
+	if _, err := conditions.evaluate(newContext(pod("default", nil))); err == nil {
+		t.Errorf("Expected an error for a JMESPath projection")
+	}
+}
+
+func TestLoad_InvalidSelector(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "policy.yaml"), []byte(`apiVersion: kyverno.io/v1
+kind: ClusterPolicy
+metadata:
+  name: require-owner
+spec:
+  rules:
+    - name: owner
+      match:
+        any:
+          - resources:
+              kinds: ["Pod"]
+              namespaceSelector:
+                matchExpressions:
+                  - {key: team, operator: Near}
+      validate:
+        pattern:
+          metadata:
+            labels:
+              owner: "?*"
+`), 0644)
+
+	_, err := Load([]string{dir})
+	if err == nil || !strings.Contains(err.Error(), "rule owner of require-owner") || !strings.Contains(err.Error(), "namespaceSelector") {
+		t.Errorf("Expected an error naming the rule and the selector, got %v", err)
+	}
+}
+
+func TestLoad_SkipsNonObjectFixtures(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "policies.yaml"), []byte(policies), 0644)
+	os.WriteFile(filepath.Join(dir, "fixtures.yaml"), []byte("- a\n- b\n"), 0644)
+
+	loaded, err := Load([]string{dir})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(loaded) != 3 {
+		t.Errorf("Expected 3 policies, got %d", len(loaded))
+	}
+}
+
+func TestPolicy_EvaluateNamespaceSelectorScope(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "policy.yaml"), []byte(`apiVersion: kyverno.io/v1
+kind: ClusterPolicy
+metadata:
+  name: require-owner
+spec:
+  rules:
+    - name: check-owner
+      match:
+        any:
+          - resources:
+              kinds: ["ClusterRole", "Namespace", "Pod"]
+              namespaceSelector:
+                matchLabels:
+                  team: "true"
+      validate:
+        pattern:
+          metadata:
+            labels:
+              owner: "?*"
+`), 0644)
+	loaded, err := Load([]string{dir})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	teamLabels := map[string]interface{}{"team": "true"}
+	tests := []struct {
+		name            string
+		obj             map[string]interface{}
+		namespaceLabels map[string]interface{}
+		findings        int
+	}{
+		{
+			name: "cluster-scoped object",
+			obj: map[string]interface{}{
+				"apiVersion": "rbac.authorization.k8s.io/v1",
+				"kind":       "ClusterRole",
+				"metadata":   map[string]interface{}{"name": "reader"},
+			},
+			findings: 1,
+		},
+		{
+			name: "namespace matched by its own labels",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Namespace",
+				"metadata":   map[string]interface{}{"name": "payments", "labels": teamLabels},
+			},
+			findings: 1,
+		},
+		{
+			name: "namespace without the label",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Namespace",
+				"metadata":   map[string]interface{}{"name": "search"},
+			},
+		},
+		{name: "pod in a selected namespace", obj: pod("payments", nil), namespaceLabels: teamLabels, findings: 1},
+		{name: "pod in another namespace", obj: pod("search", nil)},
+	}
+	for _, tt := range tests {
+		findings, err := loaded[0].Evaluate(tt.obj, tt.namespaceLabels)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", tt.name, err)
+		}
+		if len(findings) != tt.findings {
+			t.Errorf("%s: expected %d findings, got %+v", tt.name, tt.findings, findings)
+		}
+	}
+}
@@ -0,0 +1,380 @@
+package kyverno
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/wildcard"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// anchor is the kind of a Kyverno anchor wrapped around a pattern key
+type anchor int
+
+const (
+	noAnchor          anchor = iota
+	conditionAnchor          // (key): the rest of the pattern applies only if this matches
+	equalityAnchor           // =(key): if key is present, it must match
+	existenceAnchor          // ^(key): at least one list element must match
+	negationAnchor           // X(key): key must not be present
+	globalAnchor             // <(key): the whole rule applies only if this matches
+	addIfAbsentAnchor        // +(key): mutate only, ignored for validation
+)
+
+// parseAnchor splits a pattern key into its anchor and the bare key
+func parseAnchor(key string) (anchor, string) {
+	if !strings.HasSuffix(key, ")") {
+		return noAnchor, key
+	}
+	prefixes := []struct {
+		prefix string
+		anchor anchor
+	}{
+		{"=(", equalityAnchor},
+		{"^(", existenceAnchor},
+		{"X(", negationAnchor},
+		{"<(", globalAnchor},
+		{"+(", addIfAbsentAnchor},
+		{"(", conditionAnchor},
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(key, p.prefix) {
+			return p.anchor, key[len(p.prefix) : len(key)-1]
+		}
+	}
+	return noAnchor, key
+}
+
+// patternResult is the outcome of matching a value against a pattern
+type patternResult int
+
+const (
+	matched patternResult = iota
+	failed
+	skipped     // A condition anchor did not match: the pattern does not apply here
+	skippedRule // A global anchor did not match: the rule does not apply at all
+)
+
+// validatePattern matches obj against a validate.pattern. It reports the path
+// of the first mismatch, and ok if the object is valid or the pattern does
+// not apply to it.
+func validatePattern(obj map[string]interface{}, pattern interface{}) (path string, ok bool) {
+	result, path := matchValue(obj, pattern, "/")
+	return path, result != failed
+}
+
+// matchValue matches a resource value against a pattern value
+func matchValue(value, pattern interface{}, path string) (patternResult, string) {
+	switch p := pattern.(type) {
+	case map[string]interface{}:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return failed, path
+		}
+		return matchMap(m, p, path)
+
+	case []interface{}:
+		list, ok := value.([]interface{})
+		if !ok {
+			return failed, path
+		}
+		return matchList(list, p, path)
+
+	default:
+		if !matchScalar(value, pattern) {
+			return failed, path
+		}
+		return matched, path
+	}
+}
+
+// matchMap matches a resource map against a pattern map. Condition and global
+// anchors are checked first; if any of them does not match, the rest of the
+// pattern at this level is skipped.
+func matchMap(value, pattern map[string]interface{}, path string) (patternResult, string) {
+	keys := make([]string, 0, len(pattern))
+	for key := range pattern {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		p := pattern[key]
+		a, name := parseAnchor(key)
+		if a != conditionAnchor && a != globalAnchor {
+			continue
+		}
+		v, present := value[name]
+		if !present {
+			return skippedFor(a), path
+		}
+		if result, _ := matchValue(v, p, path+name+"/"); result != matched {
+			if result == skippedRule {
+				return result, path
+			}
+			return skippedFor(a), path
+		}
+	}
+
+	for _, key := range keys {
+		p := pattern[key]
+		a, name := parseAnchor(key)
+		v, present := value[name]
+		childPath := path + name + "/"
+
+		switch a {
+		case conditionAnchor, globalAnchor, addIfAbsentAnchor:
+			continue
+
+		case negationAnchor:
+			if present {
+				return failed, childPath
+			}
+
+		case equalityAnchor:
+			if !present {
+				continue
+			}
+			if result, failedAt := matchValue(v, p, childPath); result == failed || result == skippedRule {
+				return result, failedAt
+			}
+
+		case existenceAnchor:
+			list, ok := v.([]interface{})
+			if !present || !ok {
+				return failed, childPath
+			}
+			patterns, ok := p.([]interface{})
+			if !ok || len(patterns) == 0 {
+				return failed, childPath
+			}
+			found := false
+			for i, elem := range list {
+				if result, _ := matchValue(elem, patterns[0], fmt.Sprintf("%s%d/", childPath, i)); result == matched {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return failed, childPath
+			}
+
+		default:
+			if !present {
+				// "*" requires presence; a null pattern or a negated pattern
+				// (e.g. "!*") accepts a missing field
+				if p == nil || isNegatedPattern(p) {
+					continue
+				}
+				return failed, childPath
+			}
+			if result, failedAt := matchValue(v, p, childPath); result == failed || result == skippedRule {
+				return result, failedAt
+			}
+		}
+	}
+	return matched, path
+}
+
+// matchList matches a resource list against a pattern list. A pattern holding
+// a single map is applied to every element of the list; otherwise elements
+// are compared by position.
+func matchList(value, pattern []interface{}, path string) (patternResult, string) {
+	if len(pattern) == 0 {
+		return matched, path
+	}
+	if _, isMap := pattern[0].(map[string]interface{}); isMap {
+		for i, elem := range value {
+			result, failedAt := matchValue(elem, pattern[0], fmt.Sprintf("%s%d/", path, i))
+			if result == failed || result == skippedRule {
+				return result, failedAt
+			}
+		}
+		return matched, path
+	}
+
+	if len(value) < len(pattern) {
+		return failed, path
+	}
+	for i, p := range pattern {
+		result, failedAt := matchValue(value[i], p, fmt.Sprintf("%s%d/", path, i))
+		if result == failed || result == skippedRule {
+			return result, failedAt
+		}
+	}
+	return matched, path
+}
+
+// skippedFor returns the skip result for a failed condition or global anchor
+func skippedFor(a anchor) patternResult {
+	if a == globalAnchor {
+		return skippedRule
+	}
+	return skipped
+}
+
+// isNegatedPattern reports whether the pattern is a string negation such as "!*"
+func isNegatedPattern(pattern interface{}) bool {
+	s, ok := pattern.(string)
+	return ok && strings.HasPrefix(s, "!")
+}
+
+// matchScalar matches a resource value against a scalar pattern. String
+// patterns support wildcards (* and ?), the | (or) and & (and) combinators,
+// negation (!), comparison operators (>, <, >=, <=) and ranges (a-b, a!-b),
+// with numeric comparisons also accepting resource quantities (e.g. 1Gi).
+func matchScalar(value, pattern interface{}) bool {
+	switch p := pattern.(type) {
+	case nil:
+		return value == nil
+	case bool:
+		b, ok := value.(bool)
+		return ok && b == p
+	case string:
+		if strings.Contains(p, "|") {
+			for _, alt := range strings.Split(p, "|") {
+				if matchScalar(value, strings.TrimSpace(alt)) {
+					return true
+				}
+			}
+			return false
+		}
+		if strings.Contains(p, "&") {
+			for _, part := range strings.Split(p, "&") {
+				if !matchScalar(value, strings.TrimSpace(part)) {
+					return false
+				}
+			}
+			return true
+		}
+		return matchOperator(value, p)
+	default:
+		pn, ok := toFloat(pattern)
+		if !ok {
+			return fmt.Sprint(value) == fmt.Sprint(pattern)
+		}
+		vn, ok := toFloat(value)
+		return ok && vn == pn
+	}
+}
+
+// matchOperator matches a value against a single string pattern, which may
+// start with a comparison operator or describe a range
+func matchOperator(value interface{}, pattern string) bool {
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if strings.HasPrefix(pattern, op) {
+			return compareQuantity(value, strings.TrimSpace(pattern[len(op):]), op)
+		}
+	}
+	if strings.HasPrefix(pattern, "!") {
+		if value == nil {
+			return true
+		}
+		return !matchOperator(value, pattern[1:])
+	}
+	if low, high, negated, ok := parseRange(pattern); ok {
+		inRange := compareQuantity(value, low, ">=") && compareQuantity(value, high, "<=")
+		return inRange != negated
+	}
+
+	if value == nil {
+		return false
+	}
+	str := stringValue(value)
+	if pn, ok := parseQuantity(pattern); ok {
+		if vn, ok := parseQuantity(str); ok {
+			return vn.Cmp(pn) == 0
+		}
+	}
+	return wildcard.Match(pattern, str)
+}
+
+// parseRange parses "low-high" and "low!-high" range patterns
+func parseRange(pattern string) (low, high string, negated, ok bool) {
+	sep := "-"
+	if strings.Contains(pattern, "!-") {
+		sep, negated = "!-", true
+	}
+	parts := strings.SplitN(pattern, sep, 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false, false
+	}
+	if _, ok := parseQuantity(parts[0]); !ok {
+		return "", "", false, false
+	}
+	if _, ok := parseQuantity(parts[1]); !ok {
+		return "", "", false, false
+	}
+	return parts[0], parts[1], negated, true
+}
+
+// compareQuantity compares a value with an operand using op
+func compareQuantity(value interface{}, operand, op string) bool {
+	vq, ok := parseQuantity(stringValue(value))
+	if !ok {
+		return false
+	}
+	oq, ok := parseQuantity(operand)
+	if !ok {
+		return false
+	}
+	cmp := vq.Cmp(oq)
+	switch op {
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	}
+	return false
+}
+
+// parseQuantity parses numbers and Kubernetes quantities such as 500m or 1Gi
+func parseQuantity(s string) (resource.Quantity, bool) {
+	if s == "" {
+		return resource.Quantity{}, false
+	}
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return resource.Quantity{}, false
+	}
+	return q, true
+}
+
+// toFloat converts a numeric value to float64
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// stringValue formats a scalar resource value for string matching
+func stringValue(v interface{}) string {
+	switch n := v.(type) {
+	case string:
+		return n
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,35 @@
+// Package labelselector matches the labels of unstructured objects against
+// Kubernetes label selectors, for the Gatekeeper and Kyverno policy engines
+package labelselector
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Compile converts a label selector of a policy. Policies compile their
+// selectors when they are loaded, so that an invalid selector fails the load
+// instead of matching nothing. It returns nil for an unset selector.
+func Compile(selector *metav1.LabelSelector) (labels.Selector, error) {
+	if selector == nil {
+		return nil, nil
+	}
+	return metav1.LabelSelectorAsSelector(selector)
+}
+
+// Matches reports whether the labels of an unstructured object satisfy the
+// selector. A nil selector matches any labels.
+func Matches(selector labels.Selector, objLabels interface{}) bool {
+	if selector == nil {
+		return true
+	}
+	set := labels.Set{}
+	if m, ok := objLabels.(map[string]interface{}); ok {
+		for k, v := range m {
+			set[k] = fmt.Sprintf("%v", v)
+		}
+	}
+	return selector.Matches(set)
+}
@@ -0,0 +1,50 @@
+package labelselector
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCompile(t *testing.T) {
+	if s, err := Compile(nil); s != nil || err != nil {
+		t.Errorf("Expected no selector for an unset one, got %v, %v", s, err)
+	}
+
+	_, err := Compile(&metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+		{Key: "team", Operator: "Near"},
+	}})
+	if err == nil {
+		t.Errorf("Expected an error for an invalid operator")
+	}
+}
+
+func TestMatches(t *testing.T) {
+	selector, err := Compile(&metav1.LabelSelector{
+		MatchLabels: map[string]string{"team": "payments"},
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "tier", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"test"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		labels interface{}
+		match  bool
+	}{
+		{map[string]interface{}{"team": "payments"}, true},
+		{map[string]interface{}{"team": "payments", "tier": "test"}, false},
+		{map[string]interface{}{"team": "search"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := Matches(selector, tt.labels); got != tt.match {
+			t.Errorf("Matches(%v) = %v, expected %v", tt.labels, got, tt.match)
+		}
+	}
+	if !Matches(nil, nil) {
+		t.Errorf("Expected a nil selector to match")
+	}
+}
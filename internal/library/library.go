@@ -0,0 +1,27 @@
+// Package library is the default Rego library: the built-in checks of the
+// audit written as Rego policies, embedded in the binary.
+package library
+
+import (
+	"embed"
+	"fmt"
+
+	"github.com/open-policy-agent/opa/bundle"
+)
+
+//go:embed *.rego
+var files embed.FS
+
+// Bundle returns the library as an OPA bundle. Rego tests in the library are
+// included but are not evaluated as policies.
+func Bundle() (*bundle.Bundle, error) {
+	loader, err := bundle.NewFSLoader(files)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the default Rego library: %w", err)
+	}
+	b, err := bundle.NewCustomReader(loader).WithProcessAnnotations(true).Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the default Rego library: %w", err)
+	}
+	return &b, nil
+}
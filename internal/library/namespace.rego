@@ -0,0 +1,24 @@
+package devguardian.k8s
+
+# METADATA
+# title: Pod Security not enforced
+# description: >-
+#   Namespaces without an enforced Pod Security level, or with the
+#   privileged level, admit pods with any security settings.
+# related_resources:
+# - ref: https://kubernetes.io/docs/concepts/security/pod-security-admission/
+#   description: Pod Security Admission
+# custom:
+#   id: DG-NS-001
+#   severity: high
+deny[reason]{
+input.kind == "Namespace"
+not namespace_enforces_pod_security
+reason := "Namespace does not enforce PodSecurity standards or uses 'privileged' level"
+}
+
+namespace_enforces_pod_security{
+level := input.metadata.labels["pod-security.kubernetes.io/enforce"]
+level != ""
+level != "privileged"
+}
@@ -0,0 +1,21 @@
+package devguardian.k8s
+
+library_namespace = {
+"apiVersion": "v1",
+"kind": "Namespace",
+"metadata": {"name": "apps", "labels": {"pod-security.kubernetes.io/enforce": "restricted"}}
+}
+
+test_enforced_namespace_allowed{
+count(deny) == 0 with input as library_namespace
+}
+
+test_privileged_namespace_denied{
+ns := json.patch(library_namespace, [{"op": "replace", "path": "/metadata/labels/pod-security.kubernetes.io~1enforce", "value": "privileged"}])
+deny["Namespace does not enforce PodSecurity standards or uses 'privileged' level"] with input as ns
+}
+
+test_unlabelled_namespace_denied{
+ns := json.patch(library_namespace, [{"op": "remove", "path": "/metadata/labels"}])
+deny["Namespace does not enforce PodSecurity standards or uses 'privileged' level"] with input as ns
+}
@@ -0,0 +1,56 @@
+package devguardian.k8s
+
+# METADATA
+# title: Container runs as root
+# description: >-
+#   A container whose securityContext sets runAsUser to 0 runs as root, so a
+#   container breakout gives the attacker root on the node.
+# related_resources:
+# - ref: https://kubernetes.io/docs/concepts/security/pod-security-standards/#restricted
+#   description: Pod Security Standards (Restricted)
+# custom:
+#   id: DG-POD-001
+#   severity: high
+deny[reason]{
+input.kind == "Pod"
+container := input.spec.containers[_]
+container.securityContext.runAsUser == 0
+reason := {"msg": sprintf("Container '%s' runs as root user (uid 0)", [container.name]), "container": container.name}
+}
+
+# METADATA
+# title: Privileged container
+# description: >-
+#   Privileged containers run with all capabilities and full access to the
+#   host's devices, so a compromised container compromises the node.
+# related_resources:
+# - ref: https://kubernetes.io/docs/concepts/security/pod-security-standards/#baseline
+#   description: Pod Security Standards (Baseline)
+# custom:
+#   id: DG-POD-002
+#   severity: critical
+deny[reason]{
+input.kind == "Pod"
+container := input.spec.containers[_]
+container.securityContext.privileged == true
+reason := {"msg": sprintf("Container '%s' is privileged", [container.name]), "container": container.name}
+}
+
+# METADATA
+# title: hostPath volume
+# description: >-
+#   hostPath volumes expose the node's filesystem to the pod and can be used
+#   to escape the container or read other workloads' data.
+# related_resources:
+# - ref: https://kubernetes.io/docs/concepts/storage/volumes/#hostpath
+#   description: hostPath volumes
+# custom:
+#   id: DG-POD-003
+#   severity: medium
+deny[reason]{
+input.kind == "Pod"
+container := input.spec.containers[_]
+volume := input.spec.volumes[_]
+volume.hostPath
+reason := {"msg": sprintf("Container '%s' uses hostPath volume '%s'", [container.name, volume.name]), "container": container.name}
+}
@@ -0,0 +1,31 @@
+package devguardian.k8s
+
+library_pod = {
+"apiVersion": "v1",
+"kind": "Pod",
+"metadata": {"name": "web", "namespace": "default"},
+"spec": {
+"containers": [{"name": "app", "securityContext": {"runAsUser": 0, "privileged": true}}],
+"volumes": [{"name": "docker", "hostPath": {"path": "/var/run/docker.sock"}}, {"name": "tmp", "emptyDir": {}}]
+}
+}
+
+test_root_user_denied{
+deny[{"msg": "Container 'app' runs as root user (uid 0)", "container": "app"}] with input as library_pod
+}
+
+test_privileged_container_denied{
+deny[{"msg": "Container 'app' is privileged", "container": "app"}] with input as library_pod
+}
+
+test_host_path_denied{
+deny[{"msg": "Container 'app' uses hostPath volume 'docker'", "container": "app"}] with input as library_pod
+}
+
+test_hardened_pod_allowed{
+pod := json.patch(library_pod, [
+{"op": "replace", "path": "/spec/containers/0/securityContext", "value": {"runAsUser": 1000, "privileged": false}},
+{"op": "remove", "path": "/spec/volumes/0"}
+])
+count(deny) == 0 with input as pod
+}
@@ -0,0 +1,20 @@
+package devguardian.k8s
+
+# METADATA
+# title: Wildcard Role
+# description: >-
+#   A Role granting every verb on every resource gives full control over its
+#   namespace, including its Secrets.
+# related_resources:
+# - ref: https://kubernetes.io/docs/concepts/security/rbac-good-practices/
+#   description: RBAC good practices
+# custom:
+#   id: DG-RBAC-001
+#   severity: high
+deny[reason]{
+input.kind == "Role"
+rule := input.rules[_]
+rule.resources[_] == "*"
+rule.verbs[_] == "*"
+reason := "Role has wildcard resources and verbs which grants excessive permissions"
+}
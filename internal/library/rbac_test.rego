@@ -0,0 +1,20 @@
+package devguardian.k8s
+
+library_role = {
+"apiVersion": "rbac.authorization.k8s.io/v1",
+"kind": "Role",
+"metadata": {"name": "admin", "namespace": "default"},
+"rules": [
+{"apiGroups": [""], "resources": ["pods"], "verbs": ["get"]},
+{"apiGroups": ["*"], "resources": ["*"], "verbs": ["*"]}
+]
+}
+
+test_wildcard_role_denied{
+deny["Role has wildcard resources and verbs which grants excessive permissions"] with input as library_role
+}
+
+test_wildcard_verbs_only_allowed{
+role := json.patch(library_role, [{"op": "replace", "path": "/rules/1/resources", "value": ["pods"]}])
+count(deny) == 0 with input as role
+}
@@ -0,0 +1,35 @@
+package devguardian.k8s
+
+# METADATA
+# title: NodePort service
+# description: >-
+#   NodePort services open a port on every node, bypassing load balancers
+#   and firewalls placed in front of the cluster.
+# related_resources:
+# - ref: https://kubernetes.io/docs/concepts/services-networking/service/#type-nodeport
+#   description: NodePort Services
+# custom:
+#   id: DG-SVC-001
+#   severity: medium
+deny[reason]{
+input.kind == "Service"
+input.spec.type == "NodePort"
+reason := "Service uses NodePort which exposes ports on all nodes"
+}
+
+# METADATA
+# title: LoadBalancer service
+# description: >-
+#   LoadBalancer services usually get a public address, exposing the
+#   workload to the internet unless the load balancer is restricted.
+# related_resources:
+# - ref: https://kubernetes.io/docs/concepts/services-networking/service/#loadbalancer
+#   description: LoadBalancer Services
+# custom:
+#   id: DG-SVC-002
+#   severity: medium
+deny[reason]{
+input.kind == "Service"
+input.spec.type == "LoadBalancer"
+reason := "Service uses LoadBalancer which may expose the service to the internet"
+}
@@ -0,0 +1,22 @@
+package devguardian.k8s
+
+library_service = {
+"apiVersion": "v1",
+"kind": "Service",
+"metadata": {"name": "web", "namespace": "default"},
+"spec": {"type": "NodePort"}
+}
+
+test_node_port_denied{
+deny["Service uses NodePort which exposes ports on all nodes"] with input as library_service
+}
+
+test_load_balancer_denied{
+svc := json.patch(library_service, [{"op": "replace", "path": "/spec/type", "value": "LoadBalancer"}])
+deny["Service uses LoadBalancer which may expose the service to the internet"] with input as svc
+}
+
+test_cluster_ip_allowed{
+svc := json.patch(library_service, [{"op": "replace", "path": "/spec/type", "value": "ClusterIP"}])
+count(deny) == 0 with input as svc
+}
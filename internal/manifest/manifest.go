@@ -0,0 +1,183 @@
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Object is a Kubernetes object read from a manifest file, in its
+// unstructured form
+type Object struct {
+	Object map[string]interface{} // The object itself
+	File   string                 // File the object was read from ("-" for stdin)
+	Line   int                    // Line of File the object's document starts at
+}
+
+// Kind returns the kind of the object
+func (o Object) Kind() string {
+	kind, _ := o.Object["kind"].(string)
+	return kind
+}
+
+// Namespace returns the namespace of the object, if any
+func (o Object) Namespace() string {
+	metadata, _ := o.Object["metadata"].(map[string]interface{})
+	namespace, _ := metadata["namespace"].(string)
+	return namespace
+}
+
+// Name returns the name of the object
+func (o Object) Name() string {
+	metadata, _ := o.Object["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	return name
+}
+
+// Load reads the Kubernetes objects in the given files. Directories are walked
+// recursively for .yaml, .yml and .json files, and "-" reads from stdin.
+// Multi-document YAML files and v1 List objects are expanded into their
+// individual objects.
+func Load(paths []string) ([]Object, error) {
+	return load(paths, false)
+}
+
+// LoadObjects reads the Kubernetes objects in the given files like Load, but
+// skips the YAML documents that are not objects, such as the lists and
+// scalars of test fixtures kept next to policies
+func LoadObjects(paths []string) ([]Object, error) {
+	return load(paths, true)
+}
+
+// load reads the Kubernetes objects in the given files, failing on documents
+// that are not objects unless skipNonObjects is set
+func load(paths []string, skipNonObjects bool) ([]Object, error) {
+	var objects []Object
+	for _, root := range paths {
+		if root == "-" {
+			objs, err := decode(os.Stdin, root, skipNonObjects)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, objs...)
+			continue
+		}
+
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || (path != root && !isManifestFile(path)) {
+				return nil
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			objs, err := decode(f, path, skipNonObjects)
+			if err != nil {
+				return err
+			}
+			objects = append(objects, objs...)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load manifests: %w", err)
+		}
+	}
+	return objects, nil
+}
+
+// decode reads every YAML or JSON document from r
+func decode(r io.Reader, file string, skipNonObjects bool) ([]Object, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	var objects []Object
+	for _, doc := range splitDocuments(data) {
+		decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(doc.data), 4096)
+		for {
+			var value interface{}
+			if err := decoder.Decode(&value); err != nil {
+				if err == io.EOF {
+					break
+				}
+				return nil, fmt.Errorf("failed to parse %s:%d: %w", file, doc.line, err)
+			}
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				if value == nil || skipNonObjects {
+					continue
+				}
+				return nil, fmt.Errorf("failed to parse %s:%d: the document is not an object", file, doc.line)
+			}
+			// Skip empty documents, e.g. a trailing "---"
+			if len(obj) == 0 {
+				continue
+			}
+
+			// The items of a List are located at the List itself
+			if items, ok := obj["items"].([]interface{}); ok && strings.HasSuffix(fmt.Sprint(obj["kind"]), "List") {
+				for _, item := range items {
+					if m, ok := item.(map[string]interface{}); ok {
+						objects = append(objects, Object{Object: m, File: file, Line: doc.line})
+					}
+				}
+				continue
+			}
+			objects = append(objects, Object{Object: obj, File: file, Line: doc.line})
+		}
+	}
+	return objects, nil
+}
+
+// document is a YAML document of a manifest file
+type document struct {
+	data []byte
+	line int // Line of the file the document's content starts at
+}
+
+// splitDocuments splits a manifest file into its YAML documents at the lines
+// holding only "---", as the YAML decoder does, and records the line each
+// document's content starts at. Documents with no content are dropped.
+func splitDocuments(data []byte) []document {
+	var docs []document
+	current := document{}
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	for i, line := range lines {
+		trimmed := bytes.TrimSpace(line)
+		if bytes.Equal(bytes.TrimRight(line, " \t\r\n"), []byte("---")) {
+			if current.line > 0 {
+				docs = append(docs, current)
+			}
+			current = document{}
+			continue
+		}
+		if current.line == 0 && len(trimmed) > 0 && trimmed[0] != '#' {
+			current.line = i + 1
+		}
+		current.data = append(current.data, line...)
+	}
+	if current.line > 0 {
+		docs = append(docs, current)
+	}
+	return docs
+}
+
+// isManifestFile reports whether path looks like a Kubernetes manifest
+func isManifestFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
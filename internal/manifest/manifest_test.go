@@ -0,0 +1,117 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "app.yaml"), []byte(`apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+  namespace: default
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: web
+    namespace: default
+- apiVersion: v1
+  kind: Namespace
+  metadata:
+    name: default
+---
+`), 0644)
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a manifest"), 0644)
+
+	objects, err := Load([]string{dir})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(objects) != 3 {
+		t.Fatalf("Expected 3 objects, got %d", len(objects))
+	}
+
+	expected := []struct {
+		kind, namespace, name string
+		line                  int
+	}{
+		{"Pod", "default", "web", 1},
+		{"Service", "default", "web", 7},
+		{"Namespace", "", "default", 7},
+	}
+	for i, e := range expected {
+		obj := objects[i]
+		if obj.Kind() != e.kind || obj.Namespace() != e.namespace || obj.Name() != e.name {
+			t.Errorf("Expected %s/%s/%s, got %s/%s/%s", e.kind, e.namespace, e.name, obj.Kind(), obj.Namespace(), obj.Name())
+		}
+		if obj.File != filepath.Join(dir, "app.yaml") || obj.Line != e.line {
+			t.Errorf("Expected %s:%d, got %s:%d", filepath.Join(dir, "app.yaml"), e.line, obj.File, obj.Line)
+		}
+	}
+}
+
+func TestLoad_Lines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	os.WriteFile(path, []byte(`# Web tier
+---
+
+# The web pod
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+---   
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  annotations:
+    description: "--- not a separator"
+`), 0644)
+
+	objects, err := Load([]string{path})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(objects) != 2 || objects[0].Line != 5 || objects[1].Line != 10 {
+		t.Errorf("Expected objects at lines 5 and 10, got %+v", objects)
+	}
+
+	os.WriteFile(path, []byte("apiVersion: v1\nkind: Pod\n---\nkind: [\n"), 0644)
+	if _, err := Load([]string{path}); err == nil || !strings.Contains(err.Error(), "app.yaml:4") {
+		t.Errorf("Expected the error to name the document's line, got %v", err)
+	}
+}
+
+func TestLoadObjects_SkipsNonObjects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures.yaml")
+	os.WriteFile(path, []byte(`- name: a list fixture
+---
+just a string
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+`), 0644)
+
+	if _, err := Load([]string{path}); err == nil || !strings.Contains(err.Error(), "fixtures.yaml:1") {
+		t.Errorf("Expected Load to reject the list document, got %v", err)
+	}
+
+	objects, err := LoadObjects([]string{path})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(objects) != 1 || objects[0].Kind() != "Pod" || objects[0].Line != 5 {
+		t.Errorf("Expected only the Pod at line 5, got %+v", objects)
+	}
+}
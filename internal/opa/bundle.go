@@ -0,0 +1,219 @@
+package opa
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/open-policy-agent/opa/bundle"
+)
+
+const (
+	// bundleKeyID is the key ID the verification key is registered under. It
+	// takes precedence over any kid in the signature, so a single key is used
+	// for every bundle.
+	bundleKeyID = "devguardian"
+	// DefaultBundleSigningAlg is the default algorithm used to verify bundle
+	// signatures, matching the default of "opa build --signing-key"
+	DefaultBundleSigningAlg = "RS256"
+)
+
+// BundleOptions configures how policy bundles are loaded
+type BundleOptions struct {
+	// PublicKey is the path to a PEM encoded public key (or the secret for
+	// HMAC algorithms). When set, every bundle must carry a valid signature.
+	PublicKey string
+	// SigningAlg is the signature algorithm, RS256 if empty
+	SigningAlg string
+	// CacheDir is where bundles downloaded over HTTP are cached, keyed by
+	// URL together with their ETag. Defaults to devguardian/bundles in the
+	// user cache directory.
+	CacheDir string
+	// Client is the HTTP client used to download bundles. If nil, a client
+	// with a 60 second timeout is used.
+	Client *http.Client
+}
+
+// LoadBundles loads OPA bundles from each source, which may be a bundle
+// tarball (.tar.gz), a bundle directory, an http(s) URL serving a tarball or
+// a pre-built Wasm module (.wasm) exporting WasmEntrypoint. Bundles that fail
+// signature verification are rejected.
+func LoadBundles(sources []string, options BundleOptions) ([]*bundle.Bundle, error) {
+	verification, err := options.verificationConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var bundles []*bundle.Bundle
+	for _, source := range sources {
+		var b *bundle.Bundle
+		if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+			b, err = options.download(source, verification)
+		} else {
+			b, err = readBundleFile(source, verification)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load bundle %s: %w", source, err)
+		}
+		bundles = append(bundles, b)
+	}
+	return bundles, nil
+}
+
+// verificationConfig builds the signature verification config from the
+// public key, or returns nil if no key is set
+func (o BundleOptions) verificationConfig() (*bundle.VerificationConfig, error) {
+	if o.PublicKey == "" {
+		return nil, nil
+	}
+	key, err := os.ReadFile(o.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle verification key: %w", err)
+	}
+	alg := o.SigningAlg
+	if alg == "" {
+		alg = DefaultBundleSigningAlg
+	}
+	keys := map[string]*bundle.KeyConfig{
+		bundleKeyID: {Key: string(key), Algorithm: alg},
+	}
+	return bundle.NewVerificationConfig(keys, bundleKeyID, "", nil), nil
+}
+
+// readBundleFile reads a bundle from a tarball, a directory or a Wasm module
+func readBundleFile(path string, verification *bundle.VerificationConfig) (*bundle.Bundle, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return readBundle(bundle.NewDirectoryLoader(path), path, "", verification)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(path, ".wasm") {
+		if verification != nil {
+			return nil, fmt.Errorf("a plain Wasm module cannot be signed; build a signed bundle with opa build -t wasm instead")
+		}
+		return wasmBundle(path, data), nil
+	}
+	return readBundle(bundle.NewTarballLoader(bytes.NewReader(data)), path, "", verification)
+}
+
+// readBundle reads and, if verification is set, verifies a bundle
+func readBundle(loader bundle.DirectoryLoader, name, etag string, verification *bundle.VerificationConfig) (*bundle.Bundle, error) {
+	reader := bundle.NewCustomReader(loader).
+		WithBundleName(name).
+		WithBundleEtag(etag).
+		WithProcessAnnotations(true).
+		WithBundleVerificationConfig(verification)
+
+	b, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// download fetches a bundle over HTTP. The response is cached on disk with
+// its ETag, and later downloads send If-None-Match so an unchanged bundle is
+// read from the cache. Cached bundles are verified again on every load.
+func (o BundleOptions) download(url string, verification *bundle.VerificationConfig) (*bundle.Bundle, error) {
+	cacheDir := o.CacheDir
+	if cacheDir == "" {
+		userCache, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate cache directory: %w", err)
+		}
+		cacheDir = filepath.Join(userCache, "devguardian", "bundles")
+	}
+	sum := sha256.Sum256([]byte(url))
+	cachePath := filepath.Join(cacheDir, hex.EncodeToString(sum[:8])+".tar.gz")
+	etagPath := cachePath + ".etag"
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if etag, err := os.ReadFile(etagPath); err == nil {
+		if _, err := os.Stat(cachePath); err == nil {
+			req.Header.Set("If-None-Match", string(etag))
+		}
+	}
+
+	client := o.Client
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		data, err := os.ReadFile(cachePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cached bundle: %w", err)
+		}
+		etag, _ := os.ReadFile(etagPath)
+		return readBundle(bundle.NewTarballLoaderWithBaseURL(bytes.NewReader(data), url), url, string(etag), verification)
+
+	case http.StatusOK:
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download bundle: %w", err)
+		}
+		etag := resp.Header.Get("ETag")
+		// Verify before caching so a tampered download never reaches the cache
+		b, err := readBundle(bundle.NewTarballLoaderWithBaseURL(bytes.NewReader(data), url), url, etag, verification)
+		if err != nil {
+			return nil, err
+		}
+		if etag != "" {
+			if err := writeCache(cachePath, etagPath, data, etag); err != nil {
+				fmt.Printf("Warning: Failed to cache bundle %s: %v\n", url, err)
+			}
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("unexpected HTTP status %s", resp.Status)
+}
+
+// writeCache stores a downloaded bundle and its ETag
+func writeCache(cachePath, etagPath string, data []byte, etag string) error {
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return err
+	}
+	tmp := cachePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, cachePath); err != nil {
+		return err
+	}
+	return os.WriteFile(etagPath, []byte(etag), 0644)
+}
+
+// bundleModules returns the policy modules of a bundle, excluding Rego tests
+func bundleModules(b *bundle.Bundle) []bundle.ModuleFile {
+	var modules []bundle.ModuleFile
+	for _, m := range b.Modules {
+		if strings.HasSuffix(m.Path, "_test.rego") {
+			continue
+		}
+		modules = append(modules, m)
+	}
+	return modules
+}
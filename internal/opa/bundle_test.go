@@ -0,0 +1,178 @@
+package opa
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/open-policy-agent/opa/bundle"
+)
+
+const registryPolicy = `package devguardian.k8s
+
+deny[msg] {
+	input.kind == "Pod"
+	container := input.spec.containers[_]
+	not startswith(container.image, data.registry.allowed)
+	msg := sprintf("image %v is not from %v", [container.image, data.registry.allowed])
+}
+`
+
+// buildBundle returns a bundle tarball with the registry policy, signed with
+// key if it is non-nil. When tamper is set the policy is modified after
+// signing.
+func buildBundle(t *testing.T, key *rsa.PrivateKey, tamper bool) []byte {
+	t.Helper()
+	b := bundle.Bundle{
+		Data: map[string]interface{}{
+			"registry": map[string]interface{}{"allowed": "registry.example.com/"},
+		},
+		Modules: []bundle.ModuleFile{{
+			URL:  "/policies/registry.rego",
+			Path: "/policies/registry.rego",
+			Raw:  []byte(registryPolicy),
+		}},
+	}
+	if key != nil {
+		pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+		if err := b.GenerateSignature(bundle.NewSigningConfig(string(pemKey), "RS256", ""), "", false); err != nil {
+			t.Fatalf("Failed to sign bundle: %v", err)
+		}
+	}
+	if tamper {
+		b.Modules[0].Raw = []byte(strings.Replace(registryPolicy, "not startswith", "startswith", 1))
+	}
+
+	var buf bytes.Buffer
+	if err := bundle.NewWriter(&buf).DisableFormat(true).Write(b); err != nil {
+		t.Fatalf("Failed to write bundle: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// writePublicKey writes the PEM encoded public key of key to a file
+func writePublicKey(t *testing.T, dir string, key *rsa.PrivateKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("Failed to marshal public key: %v", err)
+	}
+	path := filepath.Join(dir, "public.pem")
+	os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644)
+	return path
+}
+
+func TestLoadBundles_Signature(t *testing.T) {
+	dir := t.TempDir()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	publicKey := writePublicKey(t, dir, key)
+
+	tests := []struct {
+		name    string
+		bundle  []byte
+		key     string
+		wantErr string
+	}{
+		{name: "signed", bundle: buildBundle(t, key, false), key: publicKey},
+		{name: "unsigned without key", bundle: buildBundle(t, nil, false)},
+		{name: "tampered", bundle: buildBundle(t, key, true), key: publicKey, wantErr: "digest mismatch"},
+		{name: "wrong key", bundle: buildBundle(t, otherKey, false), key: publicKey, wantErr: "failed to verify"},
+		{name: "unsigned with key", bundle: buildBundle(t, nil, false), key: publicKey, wantErr: "missing .signatures.json"},
+		{name: "signed without key", bundle: buildBundle(t, key, false), wantErr: "verification key not provided"},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "-")+".tar.gz")
+		os.WriteFile(path, tt.bundle, 0644)
+
+		bundles, err := LoadBundles([]string{path}, BundleOptions{PublicKey: tt.key})
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", tt.name, err)
+		}
+
+		evaluator, err := NewEvaluator(nil, NewInventory(), bundles...)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", tt.name, err)
+		}
+		violations, err := evaluator.Evaluate(map[string]interface{}{
+			"kind": "Pod",
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"image": "registry.example.com/web:1.0"},
+					map[string]interface{}{"image": "docker.io/nginx:1.25"},
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", tt.name, err)
+		}
+		if len(violations) != 1 || violations[0].Message != "image docker.io/nginx:1.25 is not from registry.example.com/" {
+			t.Errorf("%s: unexpected violations %v", tt.name, violations)
+		}
+	}
+}
+
+func TestLoadBundles_HTTPCache(t *testing.T) {
+	tarball := buildBundle(t, nil, false)
+	var downloads, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Header().Set("ETag", `"v1"`)
+		w.Write(tarball)
+	}))
+	defer server.Close()
+
+	options := BundleOptions{CacheDir: t.TempDir()}
+	for i := 0; i < 2; i++ {
+		bundles, err := LoadBundles([]string{server.URL + "/bundle.tar.gz"}, options)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(bundles) != 1 || len(bundles[0].Modules) != 1 {
+			t.Fatalf("Expected one bundle with one module, got %v", bundles)
+		}
+	}
+	if downloads != 1 || notModified != 1 {
+		t.Errorf("Expected 1 download and 1 cache hit, got %d and %d", downloads, notModified)
+	}
+
+	if _, err := LoadBundles([]string{server.URL + "/missing"}, BundleOptions{CacheDir: t.TempDir(), Client: &http.Client{
+		Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found", Body: http.NoBody}, nil
+		}),
+	}}); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected a 404 error, got %v", err)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
@@ -1,14 +1,361 @@
 package opa
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/bundle"
 	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/storage"
+	"github.com/open-policy-agent/opa/storage/inmem"
+	"github.com/open-policy-agent/opa/topdown"
+	"github.com/open-policy-agent/opa/topdown/lineage"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
 	"gopkg.in/yaml.v2"
 )
 
+// DefaultPolicyDir is the directory the built-in Rego policies are loaded from
+const DefaultPolicyDir = "internal/policies"
+
+// denyPackage is the package whose deny rules are evaluated for every object.
+// Policies add violation messages to the deny set in this package.
+var denyPackage = ast.MustParseRef("data.devguardian.k8s")
+
+// ExplainMode selects how much of the evaluation trace Explain returns
+type ExplainMode string
+
+const (
+	// ExplainOff disables tracing
+	ExplainOff ExplainMode = "off"
+	// ExplainFull returns the full evaluation trace
+	ExplainFull ExplainMode = "full"
+	// ExplainNotes returns only the events emitted by the trace() built-in
+	ExplainNotes ExplainMode = "notes"
+	// ExplainFails returns only the expressions that failed
+	ExplainFails ExplainMode = "fails"
+)
+
+// ParseExplainMode parses an explain mode, returning an error listing the
+// known modes
+func ParseExplainMode(s string) (ExplainMode, error) {
+	switch mode := ExplainMode(s); mode {
+	case ExplainOff, ExplainFull, ExplainNotes, ExplainFails:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown explain mode %q (expected off, full, notes or fails)", s)
+}
+
+// Violation is a single policy violation reported for an object
+type Violation struct {
+	Message   string           // Human-readable description of the violation
+	Severity  auditor.Severity // Severity set by the policy, empty if it does not set one
+	Policy    string           // Rule ID or Constraint that produced the violation, empty if unknown
+	Title     string           // Title of the rule, from its METADATA annotations
+	Container string           // Container the violation is about, empty if it is about the whole object
+}
+
+// newViolation builds a violation from a value of the deny set. Policies add
+// either a message or an object with a msg and, for violations about a single
+// container, the container's name:
+//
+//	deny[{"msg": msg, "container": container.name}]
+func newViolation(value interface{}) Violation {
+	if obj, ok := value.(map[string]interface{}); ok {
+		if msg, ok := obj["msg"].(string); ok {
+			container, _ := obj["container"].(string)
+			return Violation{Message: msg, Container: container}
+		}
+	}
+	return Violation{Message: fmt.Sprintf("%v", value)}
+}
+
+// Evaluator evaluates Kubernetes objects against a set of Rego policies. The
+// policies are compiled once and can then be evaluated against any number of
+// objects.
+type Evaluator struct {
+	rules       []*denyRule
+	wasm        []*wasmModule
+	constraints []*constraint
+	inventory   *Inventory
+}
+
+// denyRule is a single deny rule, prepared as a query of its own so that its
+// violations can be attributed to it and its metadata
+type denyRule struct {
+	meta  Rule
+	query rego.PreparedEvalQuery
+}
+
+// violationVar is bound to the head key of a deny rule in its query
+const violationVar = "devguardian_violation"
+
+// EvaluatorOptions configures an evaluator
+type EvaluatorOptions struct {
+	// Inventory is made available to the policies as data.inventory when
+	// non-nil
+	Inventory *Inventory
+	// Bundles contribute their modules and data, and their pre-built Wasm
+	// modules are evaluated with the OPA Wasm runtime
+	Bundles []*bundle.Bundle
+	// Params are made available to the policies as data.params, merged over
+	// any params defined by the bundles
+	Params Params
+	// Wasm compiles every deny rule to a Wasm module and evaluates objects
+	// through the OPA Wasm runtime
+	Wasm bool
+}
+
+// NewEvaluator compiles the policies found at policyPaths, which may be files
+// or directories containing them. Rego files contribute deny rules, and YAML
+// files may contain Gatekeeper ConstraintTemplates and Constraints.
+// When inventory is non-nil it is made available to the policies as
+// data.inventory. The modules and data of any bundles are added to those
+// loaded from policyPaths, and pre-built Wasm modules in the bundles are
+// evaluated with the OPA Wasm runtime.
+func NewEvaluator(policyPaths []string, inventory *Inventory, bundles ...*bundle.Bundle) (*Evaluator, error) {
+	return NewEvaluatorWithOptions(policyPaths, EvaluatorOptions{Inventory: inventory, Bundles: bundles})
+}
+
+// NewEvaluatorWithOptions is like NewEvaluator, with the inventory, bundles
+// and policy parameters set in options
+func NewEvaluatorWithOptions(policyPaths []string, options EvaluatorOptions) (*Evaluator, error) {
+	target := targetRego
+	if options.Wasm {
+		if !wasmEnabled {
+			return nil, ErrWasmUnsupported
+		}
+		target = targetWasm
+	}
+	bundles := options.Bundles
+	inventory := options.Inventory
+
+	sources, err := LoadModules(policyPaths)
+	if err != nil {
+		return nil, err
+	}
+	modules, err := parseModules(sources)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range bundles {
+		for _, m := range bundleModules(b) {
+			modules = append(modules, m.Parsed)
+		}
+	}
+
+	data := map[string]interface{}{}
+	for _, b := range bundles {
+		for key, value := range b.Data {
+			if _, exists := data[key]; exists {
+				return nil, fmt.Errorf("data.%s is defined by more than one bundle", key)
+			}
+			data[key] = value
+		}
+	}
+	if params := MergeParams(asObject(data["params"]), options.Params.Global); len(params) > 0 {
+		data["params"] = params
+	}
+	if inventory != nil {
+		if _, exists := data["inventory"]; exists {
+			return nil, fmt.Errorf("bundle data must not define data.inventory, which holds the scanned objects")
+		}
+		data["inventory"] = inventory.Document()
+	}
+	store := inmem.NewFromObject(data)
+
+	rules, err := prepareDenyRules(modules, store, data, options.Params, target)
+	if err != nil {
+		return nil, err
+	}
+
+	wasmModules, err := loadWasmModules(bundles, data)
+	if err != nil {
+		return nil, err
+	}
+
+	constraints, err := loadConstraints(policyPaths, store)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Evaluator{rules: rules, wasm: wasmModules, constraints: constraints, inventory: inventory}, nil
+}
+
+// prepareDenyRules compiles the modules and prepares a query for each deny
+// rule in the devguardian.k8s package. The query is the rule's body, evaluated
+// in the rule's package with its imports, so it sees the same helper rules and
+// functions as the rule itself. Rules with parameters of their own get a store
+// with those merged into data.params. With the Wasm target each query is
+// compiled to a Wasm module of its own.
+func prepareDenyRules(modules []*ast.Module, store storage.Store, data map[string]interface{}, params Params, target string) ([]*denyRule, error) {
+	annotations, errs := ast.BuildAnnotationSet(modules)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("rego compile error: %w", errs)
+	}
+
+	byName := make(map[string]*ast.Module, len(modules))
+	for i, module := range modules {
+		byName[fmt.Sprintf("%d:%s", i, module.Package.Location.File)] = module
+	}
+	compiler := ast.NewCompiler()
+	if compiler.Compile(byName); compiler.Failed() {
+		return nil, fmt.Errorf("rego compile error: %w", compiler.Errors)
+	}
+
+	var rules []*denyRule
+	for _, module := range modules {
+		for _, rule := range module.Rules {
+			if !isDenyRule(module, rule) {
+				continue
+			}
+			meta, err := ruleMetadata(annotations, rule)
+			if err != nil {
+				return nil, err
+			}
+			body := rule.Body.Copy()
+			body.Append(ast.Equality.Expr(ast.VarTerm(violationVar), rule.Head.Key.Copy()))
+
+			ruleStore := store
+			if ruleData := params.ruleParams(meta.ID, data); ruleData != nil {
+				ruleStore = inmem.NewFromObject(ruleData)
+			}
+
+			query, err := rego.New(
+				rego.ParsedQuery(body),
+				rego.ParsedPackage(module.Package),
+				rego.ParsedImports(module.Imports),
+				rego.Compiler(compiler),
+				rego.Store(ruleStore),
+				rego.Target(target),
+			).PrepareForEval(context.Background())
+			if err != nil {
+				return nil, fmt.Errorf("rego compile error: %w", err)
+			}
+			rules = append(rules, &denyRule{meta: meta, query: query})
+		}
+	}
+	return rules, nil
+}
+
+// isDenyRule reports whether rule contributes to the deny set
+func isDenyRule(module *ast.Module, rule *ast.Rule) bool {
+	return module.Package.Path.Equal(denyPackage) &&
+		rule.Head.Ref().String() == "deny" &&
+		rule.Head.Key != nil
+}
+
+// Rules returns the catalog of the rules the evaluator checks: the Rego deny
+// rules, the pre-built Wasm modules and the Gatekeeper Constraints
+func (e *Evaluator) Rules() []Rule {
+	var rules []Rule
+	for _, r := range e.rules {
+		rules = append(rules, r.meta)
+	}
+	for _, m := range e.wasm {
+		rules = append(rules, Rule{Source: "wasm", Location: m.name})
+	}
+	for _, c := range e.constraints {
+		rules = append(rules, Rule{
+			ID:       fmt.Sprintf("%s/%s", c.kind, c.name),
+			Severity: c.severity,
+			Source:   "gatekeeper",
+		})
+	}
+	return rules
+}
+
+// Evaluate evaluates a single object, in its unstructured form, and returns
+// the violations produced by the policies
+func (e *Evaluator) Evaluate(obj map[string]interface{}) ([]Violation, error) {
+	return e.eval(obj)
+}
+
+// Explain evaluates a single object like Evaluate and additionally returns
+// the evaluation trace, filtered according to mode, in the same format as
+// "opa eval --explain"
+func (e *Evaluator) Explain(obj map[string]interface{}, mode ExplainMode) ([]Violation, string, error) {
+	if mode == ExplainOff || mode == "" {
+		reasons, err := e.eval(obj)
+		return reasons, "", err
+	}
+
+	var filter func([]*topdown.Event) []*topdown.Event
+	switch mode {
+	case ExplainFull:
+		filter = lineage.Full
+	case ExplainNotes:
+		filter = lineage.Notes
+	case ExplainFails:
+		filter = lineage.Fails
+	default:
+		return nil, "", fmt.Errorf("unknown explain mode %q (expected off, full, notes or fails)", mode)
+	}
+
+	tracer := topdown.NewBufferTracer()
+	reasons, err := e.eval(obj, rego.EvalQueryTracer(tracer))
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	topdown.PrettyTraceWithLocation(&buf, filter(*tracer))
+	return reasons, buf.String(), nil
+}
+
+// eval runs every deny rule, pre-built Wasm module and matching Gatekeeper
+// Constraint against obj
+func (e *Evaluator) eval(obj map[string]interface{}, options ...rego.EvalOption) ([]Violation, error) {
+	var violations []Violation
+	for _, rule := range e.rules {
+		results, err := rule.query.Eval(context.Background(), append(options, rego.EvalInput(obj))...)
+		if err != nil {
+			return nil, fmt.Errorf("rego eval error: %w", err)
+		}
+
+		// A rule may derive the same violation from several bindings; like
+		// the deny set, report each violation once
+		seen := make(map[Violation]bool)
+		for _, result := range results {
+			violation := newViolation(result.Bindings[violationVar])
+			if seen[violation] {
+				continue
+			}
+			seen[violation] = true
+			violation.Severity = rule.meta.Severity
+			violation.Policy = rule.meta.ID
+			violation.Title = rule.meta.Title
+			violations = append(violations, violation)
+		}
+	}
+
+	for _, m := range e.wasm {
+		mv, err := m.eval(obj)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, mv...)
+	}
+
+	for _, c := range e.constraints {
+		if !c.matches(obj, e.inventory) {
+			continue
+		}
+		cv, err := c.eval(obj, options...)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, cv...)
+	}
+	return violations, nil
+}
+
+// Evaluate evaluates a single YAML resource against the policy at policyPath
+// and returns the violation messages
 func Evaluate(resourceYAML []byte, policyPath string) ([]string, error) {
 	var input map[string]interface{}
 	err := yaml.Unmarshal(resourceYAML, &input)
@@ -16,32 +363,50 @@ func Evaluate(resourceYAML []byte, policyPath string) ([]string, error) {
 		return nil, fmt.Errorf("failed to unmarshal YAML: %w", err)
 	}
 
-	policy, err := os.ReadFile(policyPath)
+	evaluator, err := NewEvaluator([]string{policyPath}, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read policy: %w", err)
+		return nil, err
 	}
-
-	ctx := context.Background()
-	query, err := rego.New(
-		rego.Query("data.devguardian.k8s.deny"),
-		rego.Module("policy.rego", string(policy)),
-	).PrepareForEval(ctx)
+	violations, err := evaluator.Evaluate(input)
 	if err != nil {
-		return nil, fmt.Errorf("rego compile error: %w", err)
+		return nil, err
 	}
 
-	results, err := query.Eval(ctx, rego.EvalInput(input))
-	if err != nil {
-		return nil, fmt.Errorf("rego eval error: %w", err)
+	var reasons []string
+	for _, v := range violations {
+		reasons = append(reasons, v.Message)
 	}
+	return reasons, nil
+}
 
-	var reasons []string
-	for _, result := range results {
-		for _, expr := range result.Expressions {
-			for _, val := range expr.Value.([]interface{}) {
-				reasons = append(reasons, val.(string))
+// LoadModules reads the Rego modules at the given paths. Directories are
+// walked recursively and every .rego file in them is loaded, except Rego test
+// files (*_test.rego). The result maps file names to module source.
+func LoadModules(paths []string) (map[string]string, error) {
+	modules := make(map[string]string)
+	for _, root := range paths {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || !isPolicyFile(path) {
+				return nil
+			}
+			src, err := os.ReadFile(path)
+			if err != nil {
+				return err
 			}
+			modules[path] = string(src)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy: %w", err)
 		}
 	}
-	return reasons, nil
+	return modules, nil
+}
+
+// isPolicyFile reports whether path is a Rego policy (and not a Rego test)
+func isPolicyFile(path string) bool {
+	return strings.HasSuffix(path, ".rego") && !strings.HasSuffix(path, "_test.rego")
 }
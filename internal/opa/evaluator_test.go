@@ -0,0 +1,169 @@
+package opa
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInventory_Document(t *testing.T) {
+	inventory := NewInventory()
+	inventory.Add(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "default"},
+	})
+	inventory.Add(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata":   map[string]interface{}{"name": "default"},
+	})
+	// Objects without a name are ignored
+	inventory.Add(map[string]interface{}{"apiVersion": "v1", "kind": "Pod"})
+
+	if len(inventory.Objects()) != 2 {
+		t.Fatalf("Expected 2 objects, got %d", len(inventory.Objects()))
+	}
+
+	doc := inventory.Document()
+	namespaced := doc["namespace"].(map[string]interface{})
+	pods := namespaced["default"].(map[string]interface{})["v1"].(map[string]interface{})["Pod"].(map[string]interface{})
+	if _, ok := pods["web"]; !ok {
+		t.Errorf("Expected pod default/web under data.inventory.namespace")
+	}
+	cluster := doc["cluster"].(map[string]interface{})
+	namespaces := cluster["v1"].(map[string]interface{})["Namespace"].(map[string]interface{})
+	if _, ok := namespaces["default"]; !ok {
+		t.Errorf("Expected namespace default under data.inventory.cluster")
+	}
+}
+
+func TestEvaluator_CrossResource(t *testing.T) {
+	// Evaluate the bundled policies against a LoadBalancer service that
+	// selects a privileged pod
+	pod := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":      "web",
+			"namespace": "default",
+			"labels":    map[string]interface{}{"app": "web"},
+		},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{
+					"name":            "nginx",
+					"securityContext": map[string]interface{}{"privileged": true},
+				},
+			},
+		},
+	}
+	svc := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "default"},
+		"spec": map[string]interface{}{
+			"type":     "LoadBalancer",
+			"selector": map[string]interface{}{"app": "web"},
+		},
+	}
+
+	inventory := NewInventory()
+	inventory.Add(pod)
+	inventory.Add(svc)
+
+	evaluator, err := NewEvaluator([]string{filepath.Join("..", "policies")}, inventory)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	violations, err := evaluator.Evaluate(svc)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(violations) != 1 {
+		t.Fatalf("Expected 1 violation, got %d: %v", len(violations), violations)
+	}
+
+	// Without a matching pod in the inventory the service is fine
+	evaluator, err = NewEvaluator([]string{filepath.Join("..", "policies")}, NewInventory())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	violations, err = evaluator.Evaluate(svc)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(violations) != 0 {
+		t.Errorf("Expected no violations, got %v", violations)
+	}
+}
+
+func TestLoadModules_SkipsTests(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "policy.rego"), []byte("package devguardian.k8s\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "policy_test.rego"), []byte("package devguardian.k8s\n"), 0644)
+
+	modules, err := LoadModules([]string{dir})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(modules) != 1 {
+		t.Errorf("Expected 1 module, got %d", len(modules))
+	}
+}
+
+func TestEvaluator_Explain(t *testing.T) {
+	pod := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "default"},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{
+					"name":            "nginx",
+					"securityContext": map[string]interface{}{"privileged": true},
+				},
+			},
+		},
+	}
+
+	evaluator, err := NewEvaluator([]string{filepath.Join("..", "policies")}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	violations, trace, err := evaluator.Explain(pod, ExplainFull)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(violations) != 1 {
+		t.Errorf("Expected 1 violation, got %v", violations)
+	}
+	if trace == "" {
+		t.Errorf("Expected a non-empty trace")
+	}
+
+	_, trace, err = evaluator.Explain(pod, ExplainOff)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if trace != "" {
+		t.Errorf("Expected no trace, got %s", trace)
+	}
+
+	if _, _, err := evaluator.Explain(pod, "verbose"); err == nil {
+		t.Errorf("Expected an error for an unknown explain mode")
+	}
+}
+
+func TestParseExplainMode(t *testing.T) {
+	for _, s := range []string{"off", "full", "notes", "fails"} {
+		if mode, err := ParseExplainMode(s); err != nil || string(mode) != s {
+			t.Errorf("ParseExplainMode(%q) = %q, %v", s, mode, err)
+		}
+	}
+	if _, err := ParseExplainMode("verbose"); err == nil {
+		t.Errorf("Expected an error for an unknown mode")
+	}
+}
@@ -0,0 +1,445 @@
+package opa
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/storage"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/labelselector"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/manifest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// gatekeeperTemplateGroup is the API group of Gatekeeper ConstraintTemplates
+	gatekeeperTemplateGroup = "templates.gatekeeper.sh"
+	// gatekeeperConstraintGroup is the API group of Gatekeeper Constraints
+	gatekeeperConstraintGroup = "constraints.gatekeeper.sh"
+	// SeverityAnnotation sets the severity of the findings produced by a
+	// Gatekeeper Constraint
+	SeverityAnnotation = "devguardian.io/severity"
+)
+
+// enforcementSeverity is the severity given to violations of a Constraint
+// without a severity annotation, based on its enforcementAction. A "scoped"
+// Constraint gets the most severe of its scopedEnforcementActions.
+var enforcementSeverity = map[string]auditor.Severity{
+	"deny":   auditor.SeverityHigh,
+	"warn":   auditor.SeverityMedium,
+	"dryrun": auditor.SeverityLow,
+}
+
+// constraintTemplate is the Rego extracted from a Gatekeeper ConstraintTemplate
+type constraintTemplate struct {
+	kind    string        // Kind of the Constraints instantiating the template
+	modules []*ast.Module // The template's Rego and its libs
+	pkg     ast.Ref       // Package of the template's Rego
+}
+
+// constraint is a Gatekeeper Constraint bound to its compiled template
+type constraint struct {
+	kind       string
+	name       string
+	severity   auditor.Severity
+	parameters map[string]interface{}
+	match      constraintMatch
+	query      rego.PreparedEvalQuery
+
+	// The selectors of match, converted when the Constraint is loaded. They
+	// are nil when unset.
+	labelSelector     labels.Selector
+	namespaceSelector labels.Selector
+}
+
+// constraintMatch is the spec.match block of a Constraint
+type constraintMatch struct {
+	Kinds []struct {
+		APIGroups []string `json:"apiGroups"`
+		Kinds     []string `json:"kinds"`
+	} `json:"kinds"`
+	Scope              string                `json:"scope"`
+	Namespaces         []string              `json:"namespaces"`
+	ExcludedNamespaces []string              `json:"excludedNamespaces"`
+	Name               string                `json:"name"`
+	LabelSelector      *metav1.LabelSelector `json:"labelSelector"`
+	NamespaceSelector  *metav1.LabelSelector `json:"namespaceSelector"`
+}
+
+// loadConstraints reads the Gatekeeper ConstraintTemplates and Constraints
+// found in YAML files under paths and compiles each Constraint against its
+// template. Other YAML documents (e.g. test fixtures) are ignored, but
+// documents with a Constraint apiVersion must be valid Constraints.
+func loadConstraints(paths []string, store storage.Store) ([]*constraint, error) {
+	var manifestPaths []string
+	for _, path := range paths {
+		if !strings.HasSuffix(path, ".rego") {
+			manifestPaths = append(manifestPaths, path)
+		}
+	}
+	objects, err := manifest.LoadObjects(manifestPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make(map[string]*constraintTemplate)
+	var constraintObjects []manifest.Object
+	for _, obj := range objects {
+		apiVersion, _ := obj.Object["apiVersion"].(string)
+		group := strings.SplitN(apiVersion, "/", 2)[0]
+		switch {
+		case group == gatekeeperTemplateGroup && obj.Kind() == "ConstraintTemplate":
+			template, err := parseConstraintTemplate(obj)
+			if err != nil {
+				return nil, err
+			}
+			templates[template.kind] = template
+		case group == gatekeeperConstraintGroup:
+			constraintObjects = append(constraintObjects, obj)
+		}
+	}
+
+	var constraints []*constraint
+	for _, obj := range constraintObjects {
+		if obj.Kind() == "" {
+			return nil, fmt.Errorf("invalid constraint %s in %s:%d: kind is required", obj.Name(), obj.File, obj.Line)
+		}
+		template, ok := templates[obj.Kind()]
+		if !ok {
+			return nil, fmt.Errorf("constraint %s/%s in %s: no ConstraintTemplate defines kind %s", obj.Kind(), obj.Name(), obj.File, obj.Kind())
+		}
+		c, err := newConstraint(obj, template, store)
+		if err != nil {
+			return nil, err
+		}
+		constraints = append(constraints, c)
+	}
+	return constraints, nil
+}
+
+// parseConstraintTemplate extracts the Rego from a ConstraintTemplate. Both the
+// legacy spec.targets[].rego/libs fields (Rego v0) and the spec.targets[].code
+// list with a Rego engine are supported.
+func parseConstraintTemplate(obj manifest.Object) (*constraintTemplate, error) {
+	var spec struct {
+		CRD struct {
+			Spec struct {
+				Names struct {
+					Kind string `json:"kind"`
+				} `json:"names"`
+			} `json:"spec"`
+		} `json:"crd"`
+		Targets []struct {
+			Target string   `json:"target"`
+			Rego   string   `json:"rego"`
+			Libs   []string `json:"libs"`
+			Code   []struct {
+				Engine string `json:"engine"`
+				Source struct {
+					Rego    string   `json:"rego"`
+					Libs    []string `json:"libs"`
+					Version string   `json:"version"`
+				} `json:"source"`
+			} `json:"code"`
+		} `json:"targets"`
+	}
+	if err := fromUnstructured(obj.Object["spec"], &spec); err != nil {
+		return nil, fmt.Errorf("invalid ConstraintTemplate %s in %s: %w", obj.Name(), obj.File, err)
+	}
+
+	kind := spec.CRD.Spec.Names.Kind
+	if kind == "" {
+		return nil, fmt.Errorf("invalid ConstraintTemplate %s in %s: spec.crd.spec.names.kind is required", obj.Name(), obj.File)
+	}
+
+	for _, target := range spec.Targets {
+		src, libs, version := target.Rego, target.Libs, ast.RegoV0
+		for _, code := range target.Code {
+			if code.Engine == "Rego" && code.Source.Rego != "" {
+				src, libs = code.Source.Rego, code.Source.Libs
+				if code.Source.Version == "v1" {
+					version = ast.RegoV1
+				}
+			}
+		}
+		if src == "" {
+			continue
+		}
+
+		template := &constraintTemplate{kind: kind}
+		for i, module := range append([]string{src}, libs...) {
+			filename := fmt.Sprintf("%s:%s", filepath.Base(obj.File), obj.Name())
+			if i > 0 {
+				filename = fmt.Sprintf("%s/lib%d", filename, i)
+			}
+			parsed, err := ast.ParseModuleWithOpts(filename, module, ast.ParserOptions{RegoVersion: version})
+			if err != nil {
+				return nil, fmt.Errorf("invalid Rego in ConstraintTemplate %s: %w", obj.Name(), err)
+			}
+			template.modules = append(template.modules, parsed)
+		}
+		template.pkg = template.modules[0].Package.Path
+		return template, nil
+	}
+
+	return nil, fmt.Errorf("ConstraintTemplate %s in %s has no Rego source", obj.Name(), obj.File)
+}
+
+// newConstraint compiles the violation query of template for a Constraint
+func newConstraint(obj manifest.Object, template *constraintTemplate, store storage.Store) (*constraint, error) {
+	var spec struct {
+		Match                    constraintMatch        `json:"match"`
+		Parameters               map[string]interface{} `json:"parameters"`
+		EnforcementAction        string                 `json:"enforcementAction"`
+		ScopedEnforcementActions []struct {
+			Action string `json:"action"`
+		} `json:"scopedEnforcementActions"`
+	}
+	if err := fromUnstructured(obj.Object["spec"], &spec); err != nil {
+		return nil, fmt.Errorf("invalid constraint %s/%s in %s: %w", obj.Kind(), obj.Name(), obj.File, err)
+	}
+
+	labelSelector, err := labelselector.Compile(spec.Match.LabelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid constraint %s/%s in %s: spec.match.labelSelector: %w", obj.Kind(), obj.Name(), obj.File, err)
+	}
+	namespaceSelector, err := labelselector.Compile(spec.Match.NamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid constraint %s/%s in %s: spec.match.namespaceSelector: %w", obj.Kind(), obj.Name(), obj.File, err)
+	}
+
+	actions := []string{spec.EnforcementAction}
+	switch spec.EnforcementAction {
+	case "":
+		actions = []string{"deny"}
+	case "scoped":
+		actions = nil
+		for _, scoped := range spec.ScopedEnforcementActions {
+			actions = append(actions, scoped.Action)
+		}
+		if len(actions) == 0 {
+			return nil, fmt.Errorf("invalid constraint %s/%s in %s: enforcementAction scoped requires scopedEnforcementActions", obj.Kind(), obj.Name(), obj.File)
+		}
+	}
+	var severity auditor.Severity
+	for _, action := range actions {
+		s, ok := enforcementSeverity[action]
+		if !ok {
+			return nil, fmt.Errorf("invalid constraint %s/%s in %s: unknown enforcementAction %q (expected deny, warn, dryrun or scoped)", obj.Kind(), obj.Name(), obj.File, action)
+		}
+		if severity == "" || s.Compare(severity) > 0 {
+			severity = s
+		}
+	}
+
+	options := []func(*rego.Rego){
+		rego.Query(template.pkg.String() + ".violation"),
+		rego.Store(store),
+	}
+	for _, module := range template.modules {
+		options = append(options, rego.ParsedModule(module))
+	}
+	query, err := rego.New(options...).PrepareForEval(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("rego compile error in ConstraintTemplate for %s: %w", template.kind, err)
+	}
+
+	metadata, _ := obj.Object["metadata"].(map[string]interface{})
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+	if s, ok := annotations[SeverityAnnotation].(string); ok && s != "" {
+		if severity, err = auditor.ParseSeverity(s); err != nil {
+			return nil, fmt.Errorf("Constraint %s/%s: %w", obj.Kind(), obj.Name(), err)
+		}
+	}
+
+	return &constraint{
+		kind:       obj.Kind(),
+		name:       obj.Name(),
+		severity:   severity,
+		parameters: spec.Parameters,
+		match:      spec.Match,
+		query:      query,
+
+		labelSelector:     labelSelector,
+		namespaceSelector: namespaceSelector,
+	}, nil
+}
+
+// matches reports whether the constraint applies to obj, following
+// Gatekeeper's match semantics
+func (c *constraint) matches(obj map[string]interface{}, inventory *Inventory) bool {
+	gvk := objectGVK(obj)
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	namespace, _ := metadata["namespace"].(string)
+	m := c.match
+
+	if len(m.Kinds) > 0 {
+		matched := false
+		for _, k := range m.Kinds {
+			if containsOrWildcard(k.APIGroups, gvk.Group) && containsOrWildcard(k.Kinds, gvk.Kind) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	// Namespaces are matched by their own name and labels for namespace-based
+	// rules
+	isNamespace := gvk.Kind == "Namespace" && gvk.Group == ""
+	if isNamespace {
+		namespace = name
+	}
+
+	switch m.Scope {
+	case "Cluster":
+		if metadata["namespace"] != nil {
+			return false
+		}
+	case "Namespaced":
+		if metadata["namespace"] == nil {
+			return false
+		}
+	}
+
+	if m.Name != "" && !globMatch(m.Name, name) {
+		return false
+	}
+	if !labelselector.Matches(c.labelSelector, metadata["labels"]) {
+		return false
+	}
+
+	// Like Gatekeeper, the namespace criteria do not apply to cluster-scoped
+	// objects other than Namespaces
+	if namespace == "" {
+		return true
+	}
+	if len(m.Namespaces) > 0 && !anyGlobMatch(m.Namespaces, namespace) {
+		return false
+	}
+	if len(m.ExcludedNamespaces) > 0 && anyGlobMatch(m.ExcludedNamespaces, namespace) {
+		return false
+	}
+	if c.namespaceSelector == nil {
+		return true
+	}
+	namespaceLabels, _ := metadata["labels"].(map[string]interface{})
+	if !isNamespace {
+		namespaceLabels = inventory.NamespaceLabels(namespace)
+	}
+	return labelselector.Matches(c.namespaceSelector, namespaceLabels)
+}
+
+// eval evaluates the constraint against obj using Gatekeeper's input shape
+func (c *constraint) eval(obj map[string]interface{}, options ...rego.EvalOption) ([]Violation, error) {
+	gvk := objectGVK(obj)
+	metadata, _ := obj["metadata"].(map[string]interface{})
+
+	parameters := c.parameters
+	if parameters == nil {
+		parameters = map[string]interface{}{}
+	}
+	input := map[string]interface{}{
+		"review": map[string]interface{}{
+			"kind": map[string]interface{}{
+				"group":   gvk.Group,
+				"version": gvk.Version,
+				"kind":    gvk.Kind,
+			},
+			"name":      metadata["name"],
+			"namespace": metadata["namespace"],
+			"object":    obj,
+		},
+		"parameters": parameters,
+	}
+
+	options = append(options, rego.EvalInput(input))
+	results, err := c.query.Eval(context.Background(), options...)
+	if err != nil {
+		return nil, fmt.Errorf("rego eval error in constraint %s/%s: %w", c.kind, c.name, err)
+	}
+
+	var violations []Violation
+	for _, result := range results {
+		for _, expr := range result.Expressions {
+			values, _ := expr.Value.([]interface{})
+			for _, val := range values {
+				msg := fmt.Sprintf("%v", val)
+				if m, ok := val.(map[string]interface{}); ok {
+					msg = fmt.Sprintf("%v", m["msg"])
+				}
+				violations = append(violations, Violation{
+					Message:  fmt.Sprintf("[%s] %s", c.name, msg),
+					Severity: c.severity,
+					Policy:   fmt.Sprintf("%s/%s", c.kind, c.name),
+				})
+			}
+		}
+	}
+	return violations, nil
+}
+
+// objectGVK returns the group, version and kind of an unstructured object
+func objectGVK(obj map[string]interface{}) schema.GroupVersionKind {
+	apiVersion, _ := obj["apiVersion"].(string)
+	kind, _ := obj["kind"].(string)
+	return schema.FromAPIVersionAndKind(apiVersion, kind)
+}
+
+// fromUnstructured converts an unstructured value into a typed struct
+func fromUnstructured(value interface{}, out interface{}) error {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		if value == nil {
+			return nil
+		}
+		return fmt.Errorf("expected an object, got %T", value)
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(m, out)
+}
+
+// containsOrWildcard reports whether values contains value or "*"
+func containsOrWildcard(values []string, value string) bool {
+	for _, v := range values {
+		if v == "*" || v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// anyGlobMatch reports whether value matches any of the patterns
+func anyGlobMatch(patterns []string, value string) bool {
+	for _, p := range patterns {
+		if globMatch(p, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// globMatch matches value against a Gatekeeper-style pattern, which may
+// contain a "*" wildcard at the start or end (e.g. "kube-*")
+func globMatch(pattern, value string) bool {
+	switch {
+	case pattern == "*":
+		return true
+	case strings.HasPrefix(pattern, "*") && strings.HasSuffix(pattern, "*") && len(pattern) > 1:
+		return strings.Contains(value, pattern[1:len(pattern)-1])
+	case strings.HasSuffix(pattern, "*"):
+		return strings.HasPrefix(value, strings.TrimSuffix(pattern, "*"))
+	case strings.HasPrefix(pattern, "*"):
+		return strings.HasSuffix(value, strings.TrimPrefix(pattern, "*"))
+	}
+	return pattern == value
+}
@@ -0,0 +1,310 @@
+package opa
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const requiredLabelsTemplate = `apiVersion: templates.gatekeeper.sh/v1
+kind: ConstraintTemplate
+metadata:
+  name: k8srequiredlabels
+spec:
+  crd:
+    spec:
+      names:
+        kind: K8sRequiredLabels
+  targets:
+    - target: admission.k8s.gatekeeper.sh
+      rego: |
+        package k8srequiredlabels
+
+        import data.lib.helpers
+
+        violation[{"msg": msg, "details": {"missing_labels": missing}}] {
+          provided := {label | input.review.object.metadata.labels[label]}
+          required := {label | label := input.parameters.labels[_]}
+          missing := required - provided
+          count(missing) > 0
+          msg := sprintf("%s is missing labels: %v", [helpers.name(input.review), missing])
+        }
+      libs:
+        - |
+          package lib.helpers
+
+          name(review) = n {
+            n := sprintf("%s/%s", [review.kind.kind, review.name])
+          }
+`
+
+const requiredLabelsConstraints = `apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sRequiredLabels
+metadata:
+  name: must-have-owner
+spec:
+  enforcementAction: warn
+  match:
+    kinds:
+      - apiGroups: [""]
+        kinds: ["Pod"]
+    excludedNamespaces: ["kube-*"]
+  parameters:
+    labels: ["owner"]
+---
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sRequiredLabels
+metadata:
+  name: team-namespaces-need-cost-center
+  annotations:
+    devguardian.io/severity: Critical
+spec:
+  match:
+    kinds:
+      - apiGroups: ["*"]
+        kinds: ["*"]
+    namespaceSelector:
+      matchLabels:
+        team: "true"
+  parameters:
+    labels: ["cost-center"]
+`
+
+func TestEvaluator_Gatekeeper(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "template.yaml"), []byte(requiredLabelsTemplate), 0644)
+	os.WriteFile(filepath.Join(dir, "constraints.yaml"), []byte(requiredLabelsConstraints), 0644)
+
+	pod := func(namespace string) map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata":   map[string]interface{}{"name": "web", "namespace": namespace},
+		}
+	}
+
+	inventory := NewInventory()
+	inventory.Add(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata": map[string]interface{}{
+			"name":   "payments",
+			"labels": map[string]interface{}{"team": "true"},
+		},
+	})
+
+	evaluator, err := NewEvaluator([]string{dir}, inventory)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		namespace  string
+		violations []Violation
+	}{
+		{
+			namespace: "default",
+			violations: []Violation{
+				{Message: `[must-have-owner] Pod/web is missing labels: {"owner"}`, Severity: "Medium", Policy: "K8sRequiredLabels/must-have-owner"},
+			},
+		},
+		{
+			namespace: "payments",
+			violations: []Violation{
+				{Message: `[must-have-owner] Pod/web is missing labels: {"owner"}`, Severity: "Medium", Policy: "K8sRequiredLabels/must-have-owner"},
+				{Message: `[team-namespaces-need-cost-center] Pod/web is missing labels: {"cost-center"}`, Severity: "Critical", Policy: "K8sRequiredLabels/team-namespaces-need-cost-center"},
+			},
+		},
+		{
+			namespace:  "kube-system",
+			violations: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		violations, err := evaluator.Evaluate(pod(tt.namespace))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(violations) != len(tt.violations) {
+			t.Errorf("%s: expected %d violations, got %v", tt.namespace, len(tt.violations), violations)
+			continue
+		}
+		for i := range violations {
+			if violations[i] != tt.violations[i] {
+				t.Errorf("%s: expected %+v, got %+v", tt.namespace, tt.violations[i], violations[i])
+			}
+		}
+	}
+}
+
+func TestEvaluator_GatekeeperMissingTemplate(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "constraints.yaml"), []byte(requiredLabelsConstraints), 0644)
+
+	if _, err := NewEvaluator([]string{dir}, nil); err == nil {
+		t.Errorf("Expected an error for a constraint without a template")
+	}
+}
+
+func TestEvaluator_GatekeeperInvalidConstraints(t *testing.T) {
+	constraint := func(spec string) string {
+		return `apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sRequiredLabels
+metadata:
+  name: broken
+spec:
+` + spec
+	}
+	tests := []struct {
+		name, constraint, err string
+	}{
+		{"unknown action", constraint("  enforcementAction: dneys\n"), `unknown enforcementAction "dneys"`},
+		{"scoped without actions", constraint("  enforcementAction: scoped\n"), "requires scopedEnforcementActions"},
+		{"unknown scoped action", constraint("  enforcementAction: scoped\n  scopedEnforcementActions:\n    - action: block\n"), `unknown enforcementAction "block"`},
+		{"label selector", constraint("  match:\n    labelSelector:\n      matchExpressions:\n        - {key: team, operator: Exists, values: [a]}\n"), "spec.match.labelSelector"},
+		{"namespace selector", constraint("  match:\n    namespaceSelector:\n      matchExpressions:\n        - {key: team, operator: Near}\n"), "spec.match.namespaceSelector"},
+		{"no kind", "apiVersion: constraints.gatekeeper.sh/v1beta1\nmetadata:\n  name: broken\n", "kind is required"},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "template.yaml"), []byte(requiredLabelsTemplate), 0644)
+		os.WriteFile(filepath.Join(dir, "constraints.yaml"), []byte(tt.constraint), 0644)
+
+		_, err := NewEvaluator([]string{dir}, nil)
+		if err == nil || !strings.Contains(err.Error(), tt.err) || !strings.Contains(err.Error(), "broken") {
+			t.Errorf("%s: expected an error naming the constraint and containing %q, got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestEvaluator_GatekeeperScopedAndFixtures(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "template.yaml"), []byte(requiredLabelsTemplate), 0644)
+	os.WriteFile(filepath.Join(dir, "constraints.yaml"), []byte(`apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sRequiredLabels
+metadata:
+  name: scoped-owner
+spec:
+  enforcementAction: scoped
+  scopedEnforcementActions:
+    - action: dryrun
+    - action: warn
+  parameters:
+    labels: ["owner"]
+`), 0644)
+	os.WriteFile(filepath.Join(dir, "fixtures.yaml"), []byte("- owner: alice\n- owner: bob\n---\nplain text\n"), 0644)
+
+	evaluator, err := NewEvaluator([]string{dir}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	violations, err := evaluator.Evaluate(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "default"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(violations) != 1 || violations[0].Severity != "Medium" {
+		t.Errorf("Expected a Medium violation, got %v", violations)
+	}
+}
+
+func TestEvaluator_GatekeeperNamespaceCriteria(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "template.yaml"), []byte(requiredLabelsTemplate), 0644)
+	os.WriteFile(filepath.Join(dir, "constraints.yaml"), []byte(`apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sRequiredLabels
+metadata:
+  name: team-owner
+spec:
+  match:
+    kinds:
+      - apiGroups: ["", "rbac.authorization.k8s.io"]
+        kinds: ["ClusterRole", "Namespace", "Pod"]
+    namespaces: ["team-*"]
+    namespaceSelector:
+      matchLabels:
+        team: "true"
+  parameters:
+    labels: ["owner"]
+`), 0644)
+
+	evaluator, err := NewEvaluator([]string{dir}, NewInventory())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		obj        map[string]interface{}
+		violations int
+	}{
+		{
+			name: "cluster-scoped object",
+			obj: map[string]interface{}{
+				"apiVersion": "rbac.authorization.k8s.io/v1",
+				"kind":       "ClusterRole",
+				"metadata":   map[string]interface{}{"name": "reader"},
+			},
+			violations: 1,
+		},
+		{
+			name: "namespace matched by its own labels",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Namespace",
+				"metadata":   map[string]interface{}{"name": "team-a", "labels": map[string]interface{}{"team": "true"}},
+			},
+			violations: 1,
+		},
+		{
+			name: "namespace without the label",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Namespace",
+				"metadata":   map[string]interface{}{"name": "team-b"},
+			},
+		},
+		{
+			name: "pod in an unselected namespace",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"metadata":   map[string]interface{}{"name": "web", "namespace": "team-a"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		violations, err := evaluator.Evaluate(tt.obj)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", tt.name, err)
+		}
+		if len(violations) != tt.violations {
+			t.Errorf("%s: expected %d violations, got %v", tt.name, tt.violations, violations)
+		}
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern, value string
+		match          bool
+	}{
+		{"kube-*", "kube-system", true},
+		{"kube-*", "default", false},
+		{"*-system", "kube-system", true},
+		{"*ube*", "kube-system", true},
+		{"*", "anything", true},
+		{"default", "default", true},
+		{"default", "default2", false},
+	}
+	for _, tt := range tests {
+		if got := globMatch(tt.pattern, tt.value); got != tt.match {
+			t.Errorf("globMatch(%q, %q) = %v, expected %v", tt.pattern, tt.value, got, tt.match)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package opa
+
+// Inventory holds every object seen during a scan so that policies can join
+// across resources. It is exposed to Rego as data.inventory using the same
+// layout as Gatekeeper's replicated (synced) data:
+//
+//	data.inventory.namespace[<namespace>][<apiVersion>][<kind>][<name>]
+//	data.inventory.cluster[<apiVersion>][<kind>][<name>]
+//
+// Namespaced objects (those with metadata.namespace set) live under
+// "namespace", everything else (Namespaces, ClusterRoles, ...) under "cluster".
+type Inventory struct {
+	namespaced map[string]interface{}
+	cluster    map[string]interface{}
+	objects    []map[string]interface{}
+}
+
+// NewInventory creates an empty inventory
+func NewInventory() *Inventory {
+	return &Inventory{
+		namespaced: make(map[string]interface{}),
+		cluster:    make(map[string]interface{}),
+	}
+}
+
+// Add records an object in the inventory. The object must be in its
+// unstructured form with apiVersion, kind and metadata.name set; objects
+// missing any of these are ignored.
+func (i *Inventory) Add(obj map[string]interface{}) {
+	apiVersion, _ := obj["apiVersion"].(string)
+	kind, _ := obj["kind"].(string)
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	namespace, _ := metadata["namespace"].(string)
+
+	if apiVersion == "" || kind == "" || name == "" {
+		return
+	}
+
+	root := i.cluster
+	if namespace != "" {
+		root = child(i.namespaced, namespace)
+	}
+	child(child(root, apiVersion), kind)[name] = obj
+	i.objects = append(i.objects, obj)
+}
+
+// Get returns the object with the given apiVersion, kind, namespace and name,
+// or nil if it is not in the inventory. Use an empty namespace for
+// cluster-scoped objects.
+func (i *Inventory) Get(apiVersion, kind, namespace, name string) map[string]interface{} {
+	if i == nil {
+		return nil
+	}
+	root := i.cluster
+	if namespace != "" {
+		root, _ = i.namespaced[namespace].(map[string]interface{})
+	}
+	byVersion, _ := root[apiVersion].(map[string]interface{})
+	byKind, _ := byVersion[kind].(map[string]interface{})
+	obj, _ := byKind[name].(map[string]interface{})
+	return obj
+}
+
+// NamespaceLabels returns the labels of the named Namespace, or nil if the
+// namespace is not in the inventory
+func (i *Inventory) NamespaceLabels(namespace string) map[string]interface{} {
+	ns := i.Get("v1", "Namespace", "", namespace)
+	metadata, _ := ns["metadata"].(map[string]interface{})
+	labels, _ := metadata["labels"].(map[string]interface{})
+	return labels
+}
+
+// Objects returns the objects in the order they were added
+func (i *Inventory) Objects() []map[string]interface{} {
+	return i.objects
+}
+
+// Document returns the inventory in the shape exposed to Rego as data.inventory
+func (i *Inventory) Document() map[string]interface{} {
+	return map[string]interface{}{
+		"namespace": i.namespaced,
+		"cluster":   i.cluster,
+	}
+}
+
+// child returns the nested map stored under key, creating it if necessary
+func child(m map[string]interface{}, key string) map[string]interface{} {
+	if c, ok := m[key].(map[string]interface{}); ok {
+		return c
+	}
+	c := make(map[string]interface{})
+	m[key] = c
+	return c
+}
@@ -0,0 +1,101 @@
+package opa
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+)
+
+// Rule describes a policy rule in the catalog. For Rego deny rules it is
+// built from the METADATA annotations on the rule, its document, its package
+// and the enclosing packages, the closest annotation taking precedence:
+//
+//	# METADATA
+//	# title: Privileged container
+//	# description: Privileged containers have full access to the host.
+//	# related_resources:
+//	# - ref: https://kubernetes.io/docs/concepts/security/pod-security-standards/
+//	# custom:
+//	#   id: DG-POD-002
+//	#   severity: critical
+//	deny[reason] { ... }
+type Rule struct {
+	ID               string            `json:"id,omitempty"`
+	Title            string            `json:"title,omitempty"`
+	Description      string            `json:"description,omitempty"`
+	Severity         auditor.Severity  `json:"severity,omitempty"`
+	RelatedResources []RelatedResource `json:"related_resources,omitempty"`
+	Source           string            `json:"source"`             // rego, gatekeeper or kyverno
+	Location         string            `json:"location,omitempty"` // file:row of a Rego rule
+}
+
+// RelatedResource is a link to further documentation on a rule
+type RelatedResource struct {
+	Ref         string `json:"ref"`
+	Description string `json:"description,omitempty"`
+}
+
+// parseModules parses Rego module sources, keeping their METADATA annotations.
+// Modules are returned sorted by file name so that rules are evaluated in a
+// stable order.
+func parseModules(sources map[string]string) ([]*ast.Module, error) {
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var modules []*ast.Module
+	for _, name := range names {
+		module, err := ast.ParseModuleWithOpts(name, sources[name], ast.ParserOptions{ProcessAnnotation: true})
+		if err != nil {
+			return nil, fmt.Errorf("rego compile error: %w", err)
+		}
+		modules = append(modules, module)
+	}
+	return modules, nil
+}
+
+// ruleMetadata builds the catalog entry of a Rego rule from its annotations.
+// An invalid custom.severity is an error.
+func ruleMetadata(annotations *ast.AnnotationSet, rule *ast.Rule) (Rule, error) {
+	meta := Rule{Source: "rego"}
+	if rule.Location != nil {
+		meta.Location = fmt.Sprintf("%s:%d", filepath.Base(rule.Location.File), rule.Location.Row)
+	}
+
+	for _, ref := range annotations.Chain(rule) {
+		a := ref.Annotations
+		if a == nil {
+			continue
+		}
+		if meta.Title == "" {
+			meta.Title = a.Title
+		}
+		if meta.Description == "" {
+			meta.Description = strings.TrimSpace(a.Description)
+		}
+		if meta.ID == "" {
+			meta.ID, _ = a.Custom["id"].(string)
+		}
+		if severity, ok := a.Custom["severity"].(string); ok && meta.Severity == "" {
+			var err error
+			if meta.Severity, err = auditor.ParseSeverity(severity); err != nil {
+				return meta, fmt.Errorf("%s: %w", meta.Location, err)
+			}
+		}
+		if meta.RelatedResources == nil {
+			for _, r := range a.RelatedResources {
+				meta.RelatedResources = append(meta.RelatedResources, RelatedResource{
+					Ref:         r.Ref.String(),
+					Description: r.Description,
+				})
+			}
+		}
+	}
+	return meta, nil
+}
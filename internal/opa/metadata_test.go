@@ -0,0 +1,110 @@
+package opa
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const annotatedPolicy = `# METADATA
+# scope: package
+# custom:
+#   severity: low
+package devguardian.k8s
+
+# METADATA
+# title: Host network
+# description: Pods must not use the host network.
+# related_resources:
+# - ref: https://kubernetes.io/docs/concepts/security/pod-security-standards/
+# custom:
+#   id: DG-TEST-001
+#   severity: HIGH
+deny[msg] {
+	input.spec.hostNetwork
+	msg := sprintf("pod %s uses the host network", [input.metadata.name])
+}
+
+deny[msg] {
+	not input.metadata.labels.owner
+	msg := "missing owner label"
+}
+`
+
+func TestEvaluator_RuleMetadata(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "annotated.rego"), []byte(annotatedPolicy), 0644)
+
+	evaluator, err := NewEvaluator([]string{dir}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	rules := evaluator.Rules()
+	if len(rules) != 2 {
+		t.Fatalf("Expected 2 rules, got %d: %v", len(rules), rules)
+	}
+	hostNetwork := rules[0]
+	if hostNetwork.ID != "DG-TEST-001" || hostNetwork.Title != "Host network" || hostNetwork.Severity != "High" ||
+		hostNetwork.Description != "Pods must not use the host network." || hostNetwork.Location != "annotated.rego:15" {
+		t.Errorf("Unexpected metadata for the annotated rule: %+v", hostNetwork)
+	}
+	if len(hostNetwork.RelatedResources) != 1 || hostNetwork.RelatedResources[0].Ref != "https://kubernetes.io/docs/concepts/security/pod-security-standards/" {
+		t.Errorf("Unexpected related resources: %+v", hostNetwork.RelatedResources)
+	}
+	if owner := rules[1]; owner.ID != "" || owner.Severity != "Low" || owner.Source != "rego" {
+		t.Errorf("Expected the package severity for the unannotated rule, got %+v", owner)
+	}
+
+	violations, err := evaluator.Evaluate(map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "web"},
+		"spec":     map[string]interface{}{"hostNetwork": true},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []Violation{
+		{Message: "pod web uses the host network", Severity: "High", Policy: "DG-TEST-001", Title: "Host network"},
+		{Message: "missing owner label", Severity: "Low"},
+	}
+	if len(violations) != len(expected) {
+		t.Fatalf("Expected %d violations, got %v", len(expected), violations)
+	}
+	for i := range expected {
+		if violations[i] != expected[i] {
+			t.Errorf("Expected %+v, got %+v", expected[i], violations[i])
+		}
+	}
+}
+
+func TestEvaluator_BundledRuleCatalog(t *testing.T) {
+	evaluator, err := NewEvaluator([]string{filepath.Join("..", "policies")}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, rule := range evaluator.Rules() {
+		if rule.ID == "" || rule.Title == "" || rule.Severity == "" || rule.Description == "" {
+			t.Errorf("Bundled rule at %s is missing metadata: %+v", rule.Location, rule)
+		}
+	}
+}
+
+func TestEvaluator_InvalidSeverity(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "invalid.rego"), []byte(`package devguardian.k8s
+
+# METADATA
+# custom:
+#   severity: severe
+deny[msg] {
+	input.spec.hostNetwork
+	msg := "host network"
+}
+`), 0644)
+
+	_, err := NewEvaluator([]string{dir}, nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid.rego") || !strings.Contains(err.Error(), `invalid severity "severe"`) {
+		t.Errorf("Expected an invalid severity error naming the file, got %v", err)
+	}
+}
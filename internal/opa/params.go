@@ -0,0 +1,98 @@
+package opa
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Params are the parameters passed to the policies as data.params, so that
+// the same policies can be reused with different allowlists and thresholds
+type Params struct {
+	// Global is data.params for every rule
+	Global map[string]interface{}
+	// Rules are merged over Global for the deny rule with the given ID only
+	Rules map[string]map[string]interface{}
+}
+
+// LoadParams reads JSON or YAML data files and merges them, in order, into a
+// single parameter document. Each file must contain an object. Nested objects
+// are merged key by key, while any other value, including lists, replaces the
+// value of an earlier file.
+func LoadParams(paths []string) (map[string]interface{}, error) {
+	params := map[string]interface{}{}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy data: %w", err)
+		}
+		var doc map[string]interface{}
+		err = yaml.NewYAMLOrJSONDecoder(f, 4096).Decode(&doc)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse policy data %s: %w", path, err)
+		}
+		params = MergeParams(params, doc)
+	}
+	return params, nil
+}
+
+// MergeParams returns the parameters of base overridden by those of
+// overrides. Nested objects are merged key by key; neither argument is
+// modified.
+func MergeParams(base, overrides map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(overrides))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overrides {
+		baseObj, baseIsObj := merged[key].(map[string]interface{})
+		obj, isObj := value.(map[string]interface{})
+		if baseIsObj && isObj {
+			merged[key] = MergeParams(baseObj, obj)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+// ruleParams returns data with the rule's parameters merged over data.params,
+// or nil if the rule has none of its own
+func (p Params) ruleParams(id string, data map[string]interface{}) map[string]interface{} {
+	overrides, ok := p.Rules[id]
+	if !ok {
+		return nil
+	}
+	ruleData := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		ruleData[key] = value
+	}
+	ruleData["params"] = MergeParams(asObject(data["params"]), overrides)
+	return ruleData
+}
+
+// UnknownRules returns the IDs, sorted, of the rules that parameters are set
+// for but that are not in the catalog, typically because of a typo
+func (p Params) UnknownRules(catalog []Rule) []string {
+	known := make(map[string]bool, len(catalog))
+	for _, r := range catalog {
+		known[r.ID] = true
+	}
+	var unknown []string
+	for id := range p.Rules {
+		if !known[id] {
+			unknown = append(unknown, id)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// asObject returns v if it is an object, or nil
+func asObject(v interface{}) map[string]interface{} {
+	obj, _ := v.(map[string]interface{})
+	return obj
+}
@@ -0,0 +1,135 @@
+package opa
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const paramsPolicy = `package devguardian.k8s
+
+# METADATA
+# custom:
+#   id: ACME-IMG-001
+deny[msg] {
+	container := input.spec.containers[_]
+	not allowed(container.image)
+	msg := sprintf("image %v is not from an approved registry", [container.image])
+}
+
+# METADATA
+# custom:
+#   id: ACME-REPLICAS-001
+deny[msg] {
+	input.spec.replicas > data.params.max_replicas
+	msg := sprintf("%v replicas exceed %v", [input.spec.replicas, data.params.max_replicas])
+}
+
+allowed(image) {
+	startswith(image, data.params.registries[_])
+}
+`
+
+func TestLoadParams(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	override := filepath.Join(dir, "prod.json")
+	os.WriteFile(base, []byte("registries: [registry.example.com/]\nlimits:\n  cpu: 2\n  memory: 4Gi\n"), 0644)
+	os.WriteFile(override, []byte(`{"registries": ["prod.example.com/"], "limits": {"cpu": 4}}`), 0644)
+
+	params, err := LoadParams([]string{base, override})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := map[string]interface{}{
+		"registries": []interface{}{"prod.example.com/"},
+		"limits":     map[string]interface{}{"cpu": float64(4), "memory": "4Gi"},
+	}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("Expected %v, got %v", want, params)
+	}
+
+	if _, err := LoadParams([]string{filepath.Join(dir, "missing.yaml")}); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestEvaluator_Params(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "params.rego"), []byte(paramsPolicy), 0644)
+
+	deployment := map[string]interface{}{
+		"kind":     "Deployment",
+		"metadata": map[string]interface{}{"name": "web"},
+		"spec": map[string]interface{}{
+			"replicas": 5,
+			"containers": []interface{}{
+				map[string]interface{}{"image": "quay.io/acme/web:1.0"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		params Params
+		want   []string
+	}{
+		{
+			name: "global",
+			params: Params{Global: map[string]interface{}{
+				"registries":   []interface{}{"registry.example.com/"},
+				"max_replicas": 3,
+			}},
+			want: []string{
+				"image quay.io/acme/web:1.0 is not from an approved registry",
+				"5 replicas exceed 3",
+			},
+		},
+		{
+			name: "per rule",
+			params: Params{
+				Global: map[string]interface{}{
+					"registries":   []interface{}{"registry.example.com/"},
+					"max_replicas": 3,
+				},
+				Rules: map[string]map[string]interface{}{
+					"ACME-IMG-001": {"registries": []interface{}{"quay.io/acme/"}},
+				},
+			},
+			// The registry override applies to ACME-IMG-001 only
+			want: []string{"5 replicas exceed 3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluator, err := NewEvaluatorWithOptions([]string{dir}, EvaluatorOptions{Params: tt.params})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			violations, err := evaluator.Evaluate(deployment)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			var messages []string
+			for _, v := range violations {
+				messages = append(messages, v.Message)
+			}
+			if !reflect.DeepEqual(messages, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, messages)
+			}
+			if unknown := tt.params.UnknownRules(evaluator.Rules()); len(unknown) != 0 {
+				t.Errorf("Expected no unknown rules, got %v", unknown)
+			}
+		})
+	}
+
+	params := Params{Rules: map[string]map[string]interface{}{"ACME-IMG-01": {}}}
+	evaluator, err := NewEvaluatorWithOptions([]string{dir}, EvaluatorOptions{Params: params})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if unknown := params.UnknownRules(evaluator.Rules()); !reflect.DeepEqual(unknown, []string{"ACME-IMG-01"}) {
+		t.Errorf("Expected the misspelled rule to be reported, got %v", unknown)
+	}
+}
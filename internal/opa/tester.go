@@ -0,0 +1,144 @@
+package opa
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/open-policy-agent/opa/cover"
+	"github.com/open-policy-agent/opa/storage"
+	"github.com/open-policy-agent/opa/tester"
+)
+
+// TestOptions configures a policy test run
+type TestOptions struct {
+	Run      string        // Regular expression selecting the tests to run
+	Coverage bool          // Whether to compute policy coverage
+	Timeout  time.Duration // Timeout for each individual test
+}
+
+// TestResult is the outcome of a single Rego test rule
+type TestResult struct {
+	Package  string        // Package the test rule lives in, e.g. data.devguardian.k8s
+	Name     string        // Name of the test rule, e.g. test_privileged_pod_denied
+	File     string        // File the test rule is defined in
+	Row      int           // Line the test rule is defined on
+	Passed   bool          // The test rule evaluated to true
+	Skipped  bool          // The test rule is marked with the todo_test_ prefix
+	Error    error         // Evaluation error, if any
+	Duration time.Duration // Time taken to run the test
+	Output   string        // Output of print() calls made by the test
+}
+
+// TestReport summarises a policy test run
+type TestReport struct {
+	Results  []TestResult
+	Passed   int
+	Failed   int
+	Errored  int
+	Skipped  int
+	Coverage *cover.Report // Coverage of the non-test modules, when requested
+}
+
+// Succeeded reports whether every test that ran passed
+func (r *TestReport) Succeeded() bool {
+	return r.Failed == 0 && r.Errored == 0
+}
+
+// RunTests discovers and runs the Rego tests (rules prefixed with test_) found
+// at paths, with OPA test semantics: every .rego file is loaded as a module,
+// and .json/.yaml files are loaded as data fixtures under the data path that
+// matches their directory.
+func RunTests(paths []string, options TestOptions) (*TestReport, error) {
+	ctx := context.Background()
+
+	modules, store, err := tester.Load(paths, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load policies: %w", err)
+	}
+
+	txn, err := store.NewTransaction(ctx, storage.TransactionParams{})
+	if err != nil {
+		return nil, err
+	}
+	defer store.Abort(ctx, txn)
+
+	runner := tester.NewRunner().
+		SetStore(store).
+		SetModules(modules).
+		CapturePrintOutput(true).
+		Filter(options.Run)
+	if options.Timeout > 0 {
+		runner = runner.SetTimeout(options.Timeout)
+	}
+
+	var coverage *cover.Cover
+	if options.Coverage {
+		coverage = cover.New()
+		runner = runner.SetCoverageQueryTracer(coverage)
+	}
+
+	ch, err := runner.RunTests(ctx, txn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run policy tests: %w", err)
+	}
+
+	report := &TestReport{}
+	for r := range ch {
+		result := TestResult{
+			Package:  r.Package,
+			Name:     r.Name,
+			Passed:   r.Pass(),
+			Skipped:  r.Skip,
+			Error:    r.Error,
+			Duration: r.Duration,
+			Output:   string(r.Output),
+		}
+		if r.Location != nil {
+			result.File = r.Location.File
+			result.Row = r.Location.Row
+		}
+
+		switch {
+		case result.Skipped:
+			report.Skipped++
+		case result.Error != nil:
+			report.Errored++
+		case result.Passed:
+			report.Passed++
+		default:
+			report.Failed++
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	sort.SliceStable(report.Results, func(i, j int) bool {
+		return report.Results[i].File < report.Results[j].File
+	})
+
+	if coverage != nil {
+		report.Coverage = policyCoverage(coverage.Report(modules))
+	}
+
+	return report, nil
+}
+
+// policyCoverage restricts a coverage report to the policy modules, dropping
+// the test modules which are always fully covered by their own run
+func policyCoverage(full cover.Report) *cover.Report {
+	report := &cover.Report{Files: make(map[string]*cover.FileReport)}
+	for name, file := range full.Files {
+		if strings.HasSuffix(name, "_test.rego") {
+			continue
+		}
+		report.Files[name] = file
+		report.CoveredLines += file.CoveredLines
+		report.NotCoveredLines += file.NotCoveredLines
+	}
+	if total := report.CoveredLines + report.NotCoveredLines; total > 0 {
+		report.Coverage = 100.0 * float64(report.CoveredLines) / float64(total)
+	}
+	return report
+}
@@ -0,0 +1,64 @@
+package opa
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunTests_BundledPolicies(t *testing.T) {
+	report, err := RunTests([]string{filepath.Join("..", "policies")}, TestOptions{Coverage: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !report.Succeeded() {
+		for _, r := range report.Results {
+			if !r.Passed {
+				t.Errorf("Policy test %s.%s failed: %v", r.Package, r.Name, r.Error)
+			}
+		}
+	}
+	if report.Passed == 0 {
+		t.Errorf("Expected bundled policy tests to run")
+	}
+	if report.Coverage == nil {
+		t.Fatalf("Expected a coverage report")
+	}
+	for file := range report.Coverage.Files {
+		if !isPolicyFile(file) {
+			t.Errorf("Expected coverage for policy files only, got %s", file)
+		}
+	}
+}
+
+func TestRunTests_Library(t *testing.T) {
+	report, err := RunTests([]string{filepath.Join("..", "library")}, TestOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, r := range report.Results {
+		if !r.Passed {
+			t.Errorf("Library test %s.%s failed: %v", r.Package, r.Name, r.Error)
+		}
+	}
+	if report.Passed == 0 {
+		t.Errorf("Expected library tests to run")
+	}
+}
+
+func TestRunTests_Failure(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "policy.rego"), []byte("package example\n\nallow = true\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "policy_test.rego"), []byte("package example\n\ntest_allow { allow }\ntest_deny { not allow }\ntodo_test_later { false }\n"), 0644)
+
+	report, err := RunTests([]string{dir}, TestOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Succeeded() {
+		t.Errorf("Expected the test run to fail")
+	}
+	if report.Passed != 1 || report.Failed != 1 || report.Skipped != 1 {
+		t.Errorf("Expected 1 passed, 1 failed and 1 skipped, got %d/%d/%d", report.Passed, report.Failed, report.Skipped)
+	}
+}
@@ -0,0 +1,126 @@
+package opa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/bundle"
+	"github.com/open-policy-agent/opa/resolver"
+	"github.com/open-policy-agent/opa/resolver/wasm"
+)
+
+const (
+	// targetRego evaluates the deny rules with the regular Rego evaluator
+	targetRego = "rego"
+	// targetWasm compiles the deny rules to Wasm and evaluates them with the
+	// OPA Wasm runtime
+	targetWasm = "wasm"
+)
+
+// WasmEntrypoint is the entrypoint a pre-built Wasm module must export, such
+// as one built with "opa build -t wasm -e devguardian/k8s/deny"
+const WasmEntrypoint = "devguardian/k8s/deny"
+
+// ErrWasmUnsupported is returned when Wasm evaluation is requested from a
+// build without the Wasm runtime
+var ErrWasmUnsupported = errors.New("this build has no Wasm runtime; rebuild with CGO_ENABLED=1 and -tags opa_wasm")
+
+// WasmEnabled reports whether this build includes the OPA Wasm runtime
+func WasmEnabled() bool {
+	return wasmEnabled
+}
+
+// NewWasmEvaluator is like NewEvaluator, but compiles every deny rule to a
+// Wasm module when it is created and evaluates objects through the OPA Wasm
+// runtime, which is sandboxed. The evaluation trace is not available.
+// Gatekeeper Constraints are still evaluated with the regular evaluator.
+func NewWasmEvaluator(policyPaths []string, inventory *Inventory, bundles ...*bundle.Bundle) (*Evaluator, error) {
+	return NewEvaluatorWithOptions(policyPaths, EvaluatorOptions{Inventory: inventory, Bundles: bundles, Wasm: true})
+}
+
+// wasmModule is a pre-built Wasm module whose entrypoint is the deny set
+type wasmModule struct {
+	name     string
+	resolver *wasm.Resolver
+}
+
+// wasmEntrypointRef is the data reference of WasmEntrypoint
+var wasmEntrypointRef = denyPackage.Append(ast.StringTerm("deny"))
+
+// loadWasmModules instantiates the pre-built Wasm modules of the bundles,
+// with data as their data document
+func loadWasmModules(bundles []*bundle.Bundle, data map[string]interface{}) ([]*wasmModule, error) {
+	var modules []*wasmModule
+	for _, b := range bundles {
+		for _, m := range b.WasmModules {
+			if !wasmEnabled {
+				return nil, fmt.Errorf("cannot load Wasm module %s: %w", m.URL, ErrWasmUnsupported)
+			}
+			if !hasDenyEntrypoint(b.Manifest, m.Path) {
+				return nil, fmt.Errorf("Wasm module %s does not export the %s entrypoint", m.URL, WasmEntrypoint)
+			}
+			r, err := wasm.New([]ast.Ref{wasmEntrypointRef}, m.Raw, data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load Wasm module %s: %w", m.URL, err)
+			}
+			modules = append(modules, &wasmModule{name: m.URL, resolver: r})
+		}
+	}
+	return modules, nil
+}
+
+// hasDenyEntrypoint reports whether the manifest declares WasmEntrypoint for
+// the module at path
+func hasDenyEntrypoint(manifest bundle.Manifest, path string) bool {
+	for _, r := range manifest.WasmResolvers {
+		if strings.TrimPrefix(r.Module, "/") == strings.TrimPrefix(path, "/") && r.Entrypoint == WasmEntrypoint {
+			return true
+		}
+	}
+	return false
+}
+
+// eval evaluates obj with the module and returns the deny set. Pre-built
+// modules carry no rule metadata, so the violations are not attributed to a
+// rule.
+func (m *wasmModule) eval(obj map[string]interface{}) ([]Violation, error) {
+	input, err := ast.InterfaceToValue(obj)
+	if err != nil {
+		return nil, err
+	}
+	result, err := m.resolver.Eval(context.Background(), resolver.Input{Ref: wasmEntrypointRef, Input: ast.NewTerm(input)})
+	if err != nil {
+		return nil, fmt.Errorf("wasm eval error in %s: %w", m.name, err)
+	}
+	set, ok := result.Value.(ast.Set)
+	if !ok {
+		return nil, nil
+	}
+
+	var violations []Violation
+	err = set.Sorted().Iter(func(term *ast.Term) error {
+		value, err := ast.JSON(term.Value)
+		if err != nil {
+			return err
+		}
+		violations = append(violations, newViolation(value))
+		return nil
+	})
+	return violations, err
+}
+
+// wasmBundle wraps a pre-built Wasm module in a bundle that declares its
+// deny entrypoint
+func wasmBundle(path string, raw []byte) *bundle.Bundle {
+	module := "/" + bundle.WasmFile
+	return &bundle.Bundle{
+		Manifest: bundle.Manifest{
+			WasmResolvers: []bundle.WasmResolver{{Entrypoint: WasmEntrypoint, Module: module}},
+		},
+		Data:        map[string]interface{}{},
+		WasmModules: []bundle.WasmModuleFile{{URL: path, Path: module, Raw: raw}},
+	}
+}
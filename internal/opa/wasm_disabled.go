@@ -0,0 +1,5 @@
+//go:build !opa_wasm
+
+package opa
+
+const wasmEnabled = false
@@ -0,0 +1,14 @@
+//go:build !opa_wasm
+
+package opa
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewWasmEvaluator_Unsupported(t *testing.T) {
+	if _, err := NewWasmEvaluator(nil, nil); !errors.Is(err, ErrWasmUnsupported) {
+		t.Errorf("Expected ErrWasmUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build opa_wasm
+
+package opa
+
+// The OPA Wasm runtime is built on wasmtime and requires cgo, so it is only
+// linked in when building with the opa_wasm tag
+import _ "github.com/open-policy-agent/opa/features/wasm"
+
+const wasmEnabled = true
@@ -0,0 +1,142 @@
+//go:build opa_wasm
+
+package opa
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/bundle"
+	"github.com/open-policy-agent/opa/compile"
+)
+
+// registryBundle returns the registry policy as a bundle
+func registryBundle(t *testing.T) *bundle.Bundle {
+	t.Helper()
+	parsed, err := ast.ParseModule("/policies/registry.rego", registryPolicy)
+	if err != nil {
+		t.Fatalf("Failed to parse policy: %v", err)
+	}
+	return &bundle.Bundle{
+		Data: map[string]interface{}{
+			"registry": map[string]interface{}{"allowed": "registry.example.com/"},
+		},
+		Modules: []bundle.ModuleFile{{
+			URL:    "/policies/registry.rego",
+			Path:   "/policies/registry.rego",
+			Raw:    []byte(registryPolicy),
+			Parsed: parsed,
+		}},
+	}
+}
+
+// registryPod returns a pod with one allowed and one disallowed image
+func registryPod() map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "default"},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "registry.example.com/app:1.0"},
+				map[string]interface{}{"name": "sidecar", "image": "docker.io/proxy:latest"},
+			},
+		},
+	}
+}
+
+func TestNewWasmEvaluator(t *testing.T) {
+	regoEvaluator, err := NewEvaluator(nil, nil, registryBundle(t))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	wasmEvaluator, err := NewWasmEvaluator(nil, nil, registryBundle(t))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want, err := regoEvaluator.Evaluate(registryPod())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	got, err := wasmEvaluator.Evaluate(registryPod())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(want) != 1 {
+		t.Fatalf("Expected 1 violation from the Rego evaluator, got %+v", want)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Wasm evaluator returned %+v, Rego evaluator %+v", got, want)
+	}
+}
+
+func TestLoadBundles_WasmModule(t *testing.T) {
+	compiler := compile.New().
+		WithTarget(compile.TargetWasm).
+		WithEntrypoints(WasmEntrypoint).
+		WithBundle(registryBundle(t))
+	if err := compiler.Build(context.Background()); err != nil {
+		t.Fatalf("Failed to compile policy: %v", err)
+	}
+	built := compiler.Bundle()
+
+	dir := t.TempDir()
+	var tarball bytes.Buffer
+	if err := bundle.NewWriter(&tarball).Write(*built); err != nil {
+		t.Fatalf("Failed to write bundle: %v", err)
+	}
+	bundlePath := filepath.Join(dir, "bundle.tar.gz")
+	if err := os.WriteFile(bundlePath, tarball.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A plain module has no data.json, so the policy's data comes from a
+	// bundle of its own
+	modulePath := filepath.Join(dir, "policy.wasm")
+	if err := os.WriteFile(modulePath, built.WasmModules[0].Raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, source := range []string{bundlePath, modulePath} {
+		t.Run(filepath.Base(source), func(t *testing.T) {
+			bundles, err := LoadBundles([]string{source}, BundleOptions{})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if filepath.Ext(source) == ".wasm" {
+				bundles = append(bundles, &bundle.Bundle{Data: built.Data})
+			}
+			evaluator, err := NewEvaluator(nil, nil, bundles...)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			violations, err := evaluator.Evaluate(registryPod())
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			want := []Violation{{Message: "image docker.io/proxy:latest is not from registry.example.com/"}}
+			if !reflect.DeepEqual(violations, want) {
+				t.Errorf("Expected %+v, got %+v", want, violations)
+			}
+		})
+	}
+}
+
+func TestLoadBundles_WasmModuleSigned(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.wasm")
+	if err := os.WriteFile(path, []byte("\x00asm"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(keyPath, []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadBundles([]string{path}, BundleOptions{PublicKey: keyPath, SigningAlg: "HS256"}); err == nil {
+		t.Error("Expected an error for a plain Wasm module with signature verification enabled")
+	}
+}
@@ -25,25 +25,18 @@ func (f *CLIFormatter) Format(result AuditResult) ([]byte, error) {
 
 	// Print summary
 	buf.WriteString(fmt.Sprintf("📊 SUMMARY: Found %d security issues\n", result.Summary.TotalFindings))
+	if result.Summary.Suppressed > 0 {
+		buf.WriteString(fmt.Sprintf("🔕 Suppressed: %d accepted risk(s) not listed below\n", result.Summary.Suppressed))
+	}
+	if result.Summary.Baseline > 0 {
+		buf.WriteString(fmt.Sprintf("📌 Baseline: %d known finding(s) not listed below\n", result.Summary.Baseline))
+	}
 	buf.WriteString("-------------------------------------\n")
 	
 	// Print findings by severity
 	buf.WriteString("By Severity:\n")
-	for severity, count := range result.Summary.BySeverity {
-		var icon string
-		switch strings.ToLower(severity) {
-		case "critical":
-			icon = "🔴"
-		case "high":
-			icon = "🟠"
-		case "medium":
-			icon = "🟡"
-		case "low":
-			icon = "🟢"
-		default:
-			icon = "⚪"
-		}
-		buf.WriteString(fmt.Sprintf("  %s %s: %d\n", icon, severity, count))
+	for _, severity := range severityOrder(result.Summary.BySeverity) {
+		buf.WriteString(fmt.Sprintf("  %s %s: %d\n", severityIcon(severity), severity, result.Summary.BySeverity[severity]))
 	}
 	
 	// Print findings by resource
@@ -61,25 +54,19 @@ func (f *CLIFormatter) Format(result AuditResult) ([]byte, error) {
 	for i, exp := range result.Explanations {
 		finding := exp.Finding
 		
-		// Determine severity icon
-		var severityIcon string
-		switch strings.ToLower(finding.Severity) {
-		case "critical":
-			severityIcon = "🔴 CRITICAL"
-		case "high":
-			severityIcon = "🟠 HIGH"
-		case "medium":
-			severityIcon = "🟡 MEDIUM"
-		case "low":
-			severityIcon = "🟢 LOW"
-		default:
-			severityIcon = "⚪ " + finding.Severity
-		}
-		
 		// Print finding header
-		buf.WriteString(fmt.Sprintf("FINDING #%d: %s\n", i+1, severityIcon))
+		buf.WriteString(fmt.Sprintf("FINDING #%d: %s %s\n", i+1, severityIcon(finding.Severity), strings.ToUpper(string(finding.Severity))))
 		buf.WriteString(fmt.Sprintf("Resource: %s/%s/%s\n", finding.Resource, finding.Namespace, finding.Name))
+		if finding.Container != "" {
+			buf.WriteString(fmt.Sprintf("Container: %s\n", finding.Container))
+		}
+		if finding.RuleID != "" {
+			buf.WriteString(fmt.Sprintf("Rule: %s\n", ruleLabel(finding)))
+		}
 		buf.WriteString(fmt.Sprintf("Issue: %s\n", finding.Reason))
+		if finding.Fingerprint != "" {
+			buf.WriteString(fmt.Sprintf("Fingerprint: %s\n", finding.Fingerprint))
+		}
 		buf.WriteString("-------------------------------------\n")
 		
 		// Print explanation
@@ -101,5 +88,15 @@ func (f *CLIFormatter) Format(result AuditResult) ([]byte, error) {
 		buf.WriteString("\n=====================================\n\n")
 	}
 
+	// Print the baseline findings that are no longer found
+	if len(result.Fixed) > 0 {
+		buf.WriteString(fmt.Sprintf("✅ FIXED SINCE BASELINE: %d\n", len(result.Fixed)))
+		buf.WriteString("=====================================\n")
+		for _, finding := range result.Fixed {
+			buf.WriteString(fmt.Sprintf("  - %s/%s/%s: %s\n", finding.Resource, finding.Namespace, finding.Name, finding.Reason))
+		}
+		buf.WriteString("\n")
+	}
+
 	return buf.Bytes(), nil
 }
@@ -0,0 +1,251 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"text/tabwriter"
+	"time"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+)
+
+// Diff is the comparison of the findings of two audit reports
+type Diff struct {
+	OldReport       string                 // Path of the old report
+	NewReport       string                 // Path of the new report
+	New             []auditor.AuditFinding // Findings only in the new report
+	Resolved        []auditor.AuditFinding // Findings only in the old report
+	Unchanged       []auditor.AuditFinding // Findings in both reports with the same severity
+	SeverityChanged []SeverityChange       // Findings in both reports with a different severity
+	Summary         DiffSummary            // Number of findings in each category
+}
+
+// SeverityChange is a finding whose severity differs between two reports
+type SeverityChange struct {
+	Finding     auditor.AuditFinding // The finding in the new report
+	OldSeverity auditor.Severity     // The severity in the old report
+}
+
+// DiffSummary counts the findings of a diff in each category
+type DiffSummary struct {
+	New             int
+	Resolved        int
+	Unchanged       int
+	SeverityChanged int
+}
+
+// DiffFormatter is implemented by the formatters that can format a diff
+type DiffFormatter interface {
+	// FormatDiff formats the diff of two reports
+	FormatDiff(diff Diff) ([]byte, error)
+}
+
+// NewDiffFormatter creates a diff formatter for the provided format
+func NewDiffFormatter(format Format) (DiffFormatter, error) {
+	switch format {
+	case FormatCLI:
+		return NewCLIFormatter(), nil
+	case FormatJSON:
+		return NewJSONFormatter(), nil
+	case FormatMarkdown:
+		return NewMarkdownFormatter(), nil
+	case FormatHTML:
+		return NewHTMLFormatter(), nil
+	default:
+		return nil, fmt.Errorf("unknown diff format %q (expected cli, json, markdown or html)", format)
+	}
+}
+
+// summarize sets the summary of the diff from its findings
+func (d *Diff) summarize() {
+	d.Summary = DiffSummary{
+		New:             len(d.New),
+		Resolved:        len(d.Resolved),
+		Unchanged:       len(d.Unchanged),
+		SeverityChanged: len(d.SeverityChanged),
+	}
+}
+
+// NewDiff returns the diff of the findings of two reports. Findings are
+// matched by fingerprint, one for one, so a second occurrence of a finding is
+// new.
+func NewDiff(oldFindings, newFindings []auditor.AuditFinding) Diff {
+	var diff Diff
+	old := make(map[string][]auditor.AuditFinding)
+	for _, f := range oldFindings {
+		old[f.Fingerprint] = append(old[f.Fingerprint], f)
+	}
+	for _, f := range newFindings {
+		matches := old[f.Fingerprint]
+		if len(matches) == 0 {
+			diff.New = append(diff.New, f)
+			continue
+		}
+		previous := matches[0]
+		old[f.Fingerprint] = matches[1:]
+		if previous.Severity == f.Severity {
+			diff.Unchanged = append(diff.Unchanged, f)
+		} else {
+			diff.SeverityChanged = append(diff.SeverityChanged, SeverityChange{Finding: f, OldSeverity: previous.Severity})
+		}
+	}
+	for _, f := range oldFindings {
+		if matches := old[f.Fingerprint]; len(matches) > 0 {
+			diff.Resolved = append(diff.Resolved, matches[0])
+			old[f.Fingerprint] = matches[1:]
+		}
+	}
+	diff.summarize()
+	return diff
+}
+
+// FormatDiff formats the diff as a summary table followed by the changed
+// findings
+func (f *CLIFormatter) FormatDiff(diff Diff) ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteString("\n")
+	buf.WriteString("🔍 KUBERNETES SECURITY AUDIT DIFF\n")
+	buf.WriteString("=====================================\n")
+	buf.WriteString(fmt.Sprintf("Old: %s\nNew: %s\n\n", diff.OldReport, diff.NewReport))
+
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHANGE\tFINDINGS")
+	fmt.Fprintf(w, "🆕 New\t%d\n", diff.Summary.New)
+	fmt.Fprintf(w, "✅ Resolved\t%d\n", diff.Summary.Resolved)
+	fmt.Fprintf(w, "🔀 Severity changed\t%d\n", diff.Summary.SeverityChanged)
+	fmt.Fprintf(w, "➖ Unchanged\t%d\n", diff.Summary.Unchanged)
+	w.Flush()
+
+	writeFindings := func(title string, findings []auditor.AuditFinding) {
+		if len(findings) == 0 {
+			return
+		}
+		buf.WriteString(fmt.Sprintf("\n%s\n-------------------------------------\n", title))
+		for _, finding := range findings {
+			buf.WriteString(fmt.Sprintf("  %s %s %s/%s/%s: %s\n", severityIcon(finding.Severity), finding.Severity, finding.Resource, finding.Namespace, finding.Name, finding.Reason))
+		}
+	}
+	writeFindings("🆕 NEW FINDINGS", diff.New)
+	writeFindings("✅ RESOLVED FINDINGS", diff.Resolved)
+	if len(diff.SeverityChanged) > 0 {
+		buf.WriteString("\n🔀 SEVERITY CHANGED\n-------------------------------------\n")
+		for _, change := range diff.SeverityChanged {
+			finding := change.Finding
+			buf.WriteString(fmt.Sprintf("  %s → %s %s/%s/%s: %s\n", change.OldSeverity, finding.Severity, finding.Resource, finding.Namespace, finding.Name, finding.Reason))
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// FormatDiff formats the diff as JSON
+func (f *JSONFormatter) FormatDiff(diff Diff) ([]byte, error) {
+	return json.MarshalIndent(diff, "", "  ")
+}
+
+// FormatDiff formats the diff as HTML
+func (f *HTMLFormatter) FormatDiff(diff Diff) ([]byte, error) {
+	var buf bytes.Buffer
+
+	tmpl, err := template.New("diff").Funcs(template.FuncMap{
+		"severityClass": severityClass,
+		"severityIcon":  severityIcon,
+	}).Parse(htmlDiffTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	data := struct {
+		Diff      Diff
+		Timestamp string
+	}{
+		Diff:      diff,
+		Timestamp: time.Now().Format("2006-01-02 15:04:05"),
+	}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// HTML template for the diff
+const htmlDiffTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Kubernetes Security Audit Diff</title>
+    <style>
+` + htmlStyle + `        table {
+            border-collapse: collapse;
+            width: 100%;
+            margin-bottom: 30px;
+        }
+        th, td {
+            text-align: left;
+            padding: 8px;
+            border-bottom: 1px solid #eee;
+        }
+    </style>
+</head>
+<body>
+    <header>
+        <h1>Kubernetes Security Audit Diff</h1>
+        <p>{{.Diff.OldReport}} → {{.Diff.NewReport}}</p>
+        <p>Generated on: {{.Timestamp}}</p>
+    </header>
+
+    <section class="summary">
+        <div class="summary-box"><h2>New</h2><p><strong>{{.Diff.Summary.New}}</strong></p></div>
+        <div class="summary-box"><h2>Resolved</h2><p><strong>{{.Diff.Summary.Resolved}}</strong></p></div>
+        <div class="summary-box"><h2>Severity Changed</h2><p><strong>{{.Diff.Summary.SeverityChanged}}</strong></p></div>
+        <div class="summary-box"><h2>Unchanged</h2><p><strong>{{.Diff.Summary.Unchanged}}</strong></p></div>
+    </section>
+
+    {{define "findings"}}
+    <table>
+        <tr><th>Severity</th><th>Resource</th><th>Rule</th><th>Issue</th></tr>
+        {{range .}}
+        <tr>
+            <td><span class="severity {{severityClass .Severity}}">{{severityIcon .Severity}} {{.Severity}}</span></td>
+            <td>{{.Resource}}/{{.Namespace}}/{{.Name}}</td>
+            <td>{{.RuleID}}</td>
+            <td>{{.Reason}}</td>
+        </tr>
+        {{end}}
+    </table>
+    {{end}}
+
+    {{if .Diff.New}}
+    <h2>New Findings</h2>
+    {{template "findings" .Diff.New}}
+    {{end}}
+
+    {{if .Diff.Resolved}}
+    <h2>Resolved Findings</h2>
+    {{template "findings" .Diff.Resolved}}
+    {{end}}
+
+    {{if .Diff.SeverityChanged}}
+    <h2>Severity Changed</h2>
+    <table>
+        <tr><th>Severity</th><th>Resource</th><th>Rule</th><th>Issue</th></tr>
+        {{range .Diff.SeverityChanged}}
+        <tr>
+            <td>{{.OldSeverity}} → <span class="severity {{severityClass .Finding.Severity}}">{{severityIcon .Finding.Severity}} {{.Finding.Severity}}</span></td>
+            <td>{{.Finding.Resource}}/{{.Finding.Namespace}}/{{.Finding.Name}}</td>
+            <td>{{.Finding.RuleID}}</td>
+            <td>{{.Finding.Reason}}</td>
+        </tr>
+        {{end}}
+    </table>
+    {{end}}
+
+    <footer>
+        <p>Generated by K8s DevGuardian AI - An AI-powered Kubernetes security auditing tool</p>
+    </footer>
+</body>
+</html>`
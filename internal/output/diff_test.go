@@ -0,0 +1,72 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+)
+
+func TestNewDiff(t *testing.T) {
+	finding := func(name, fingerprint string, severity auditor.Severity) auditor.AuditFinding {
+		return auditor.AuditFinding{Resource: "Pod", Namespace: "default", Name: name, Reason: "Reason | with a pipe", Severity: severity, Fingerprint: fingerprint}
+	}
+	oldFindings := []auditor.AuditFinding{
+		finding("kept", "a", auditor.SeverityHigh),
+		finding("fixed", "b", auditor.SeverityMedium),
+		finding("escalated", "c", auditor.SeverityMedium),
+	}
+	newFindings := []auditor.AuditFinding{
+		finding("kept", "a", auditor.SeverityHigh),
+		finding("escalated", "c", auditor.SeverityCritical),
+		finding("added", "d", auditor.SeverityLow),
+		finding("kept", "a", auditor.SeverityHigh),
+	}
+
+	diff := NewDiff(oldFindings, newFindings)
+	if diff.Summary != (DiffSummary{New: 2, Resolved: 1, Unchanged: 1, SeverityChanged: 1}) {
+		t.Fatalf("Unexpected summary %+v", diff.Summary)
+	}
+	if diff.New[0].Name != "added" || diff.New[1].Name != "kept" || diff.Resolved[0].Name != "fixed" || diff.Unchanged[0].Name != "kept" {
+		t.Errorf("Unexpected classification %+v", diff)
+	}
+	if change := diff.SeverityChanged[0]; change.OldSeverity != auditor.SeverityMedium || change.Finding.Severity != auditor.SeverityCritical {
+		t.Errorf("Unexpected severity change %+v", change)
+	}
+
+	diff.OldReport, diff.NewReport = "old.json", "new.json"
+	for format, expected := range map[Format][]string{
+		FormatCLI:      {"Old: old.json", "🆕 New", "Medium → Critical Pod/default/escalated"},
+		FormatMarkdown: {"`old.json` → `new.json`", "| 🆕 New | 2 |", "Reason \\| with a pipe", "| Medium → 🔴 Critical |"},
+		FormatHTML:     {"old.json → new.json", "<h2>Severity Changed</h2>", "Pod/default/fixed"},
+	} {
+		formatter, err := NewDiffFormatter(format)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		data, err := formatter.FormatDiff(diff)
+		if err != nil {
+			t.Fatalf("Expected no error formatting %s, got %v", format, err)
+		}
+		for _, s := range expected {
+			if !strings.Contains(string(data), s) {
+				t.Errorf("Expected the %s diff to contain %q", format, s)
+			}
+		}
+	}
+
+	formatter, _ := NewDiffFormatter(FormatJSON)
+	data, err := formatter.FormatDiff(diff)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var decoded Diff
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Summary != diff.Summary {
+		t.Errorf("Expected the JSON diff to round-trip, got %+v, %v", decoded.Summary, err)
+	}
+
+	if _, err := NewDiffFormatter("sarif"); err == nil {
+		t.Error("Expected an error for an unsupported diff format")
+	}
+}
@@ -1,6 +1,10 @@
 package output
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/vibhordubey333/k8s-devguardian-ai/internal/ai"
 	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
 )
@@ -15,20 +19,58 @@ const (
 	FormatJSON Format = "json"
 	// FormatHTML represents the HTML output format
 	FormatHTML Format = "html"
+	// FormatMarkdown represents the Markdown output format
+	FormatMarkdown Format = "markdown"
+	// FormatSARIF represents the SARIF 2.1.0 output format
+	FormatSARIF Format = "sarif"
+	// FormatJUnit represents the JUnit XML output format
+	FormatJUnit Format = "junit"
+	// FormatPolicyReport represents the wgpolicyk8s.io PolicyReport output
+	// format
+	FormatPolicyReport Format = "policyreport"
+	// FormatCSV represents the CSV output format
+	FormatCSV Format = "csv"
+	// FormatTSV represents the tab-separated output format
+	FormatTSV Format = "tsv"
+	// FormatTemplate represents output rendered from a user-supplied template
+	FormatTemplate Format = "template"
 )
 
+// Formats are the formats of audit reports
+var Formats = []Format{
+	FormatCLI, FormatJSON, FormatHTML, FormatSARIF, FormatJUnit, FormatMarkdown,
+	FormatPolicyReport, FormatCSV, FormatTSV, FormatTemplate,
+}
+
+// ParseFormat returns the audit report format named s
+func ParseFormat(s string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == s {
+			return format, nil
+		}
+	}
+	names := make([]string, len(Formats))
+	for i, format := range Formats {
+		names[i] = string(format)
+	}
+	return "", fmt.Errorf("unknown format %q (expected one of %s)", s, strings.Join(names, ", "))
+}
+
 // AuditResult represents the result of an audit
 type AuditResult struct {
 	Findings     []auditor.AuditFinding    // The original findings
 	Explanations []ai.FindingExplanation   // The explanations for the findings
 	Summary      AuditSummary              // Summary of the audit
+	Fixed        []auditor.AuditFinding    `json:",omitempty"` // Findings of the baseline that are no longer found
 }
 
 // AuditSummary represents a summary of the audit
 type AuditSummary struct {
-	TotalFindings int            // Total number of findings
-	BySeverity    map[string]int // Number of findings by severity
+	TotalFindings int            // Total number of findings, not counting suppressed ones
+	BySeverity    map[auditor.Severity]int // Number of findings by severity
 	ByResource    map[string]int // Number of findings by resource type
+	Suppressed    int            // Number of suppressed findings
+	Baseline      int            // Number of findings already in the baseline, which are not reported
 }
 
 // Formatter is the interface that all output formatters must implement
@@ -44,24 +86,97 @@ func NewFormatter(format Format) Formatter {
 		return NewJSONFormatter()
 	case FormatHTML:
 		return NewHTMLFormatter()
+	case FormatSARIF:
+		return NewSARIFFormatter()
+	case FormatJUnit:
+		return NewJUnitFormatter()
+	case FormatMarkdown:
+		return NewMarkdownFormatter()
+	case FormatPolicyReport:
+		return NewPolicyReportFormatter()
+	case FormatCSV:
+		return NewCSVFormatter()
+	case FormatTSV:
+		return NewTSVFormatter()
+	case FormatTemplate:
+		return NewTemplateFormatter("")
 	default:
 		return NewCLIFormatter()
 	}
 }
 
-// GenerateSummary generates a summary of the audit result
+// GenerateSummary generates a summary of the audit result. Suppressed findings
+// are only counted in Suppressed.
 func GenerateSummary(findings []auditor.AuditFinding) AuditSummary {
-	bySeverity := make(map[string]int)
+	bySeverity := make(map[auditor.Severity]int)
 	byResource := make(map[string]int)
+	total, suppressed := 0, 0
 
 	for _, finding := range findings {
+		if finding.Suppressed {
+			suppressed++
+			continue
+		}
+		total++
 		bySeverity[finding.Severity]++
 		byResource[finding.Resource]++
 	}
 
 	return AuditSummary{
-		TotalFindings: len(findings),
+		TotalFindings: total,
 		BySeverity:    bySeverity,
 		ByResource:    byResource,
+		Suppressed:    suppressed,
+	}
+}
+
+// ruleLabel returns the rule ID of a finding followed by the rule's title, if
+// it has one
+func ruleLabel(finding auditor.AuditFinding) string {
+	if finding.Title == "" {
+		return finding.RuleID
+	}
+	return fmt.Sprintf("%s (%s)", finding.RuleID, finding.Title)
+}
+
+// severityIcon returns the icon shown next to a severity
+func severityIcon(severity auditor.Severity) string {
+	switch severity {
+	case auditor.SeverityCritical:
+		return "🔴"
+	case auditor.SeverityHigh:
+		return "🟠"
+	case auditor.SeverityMedium:
+		return "🟡"
+	case auditor.SeverityLow:
+		return "🟢"
+	default:
+		return "⚪"
+	}
+}
+
+// severityClass returns the CSS class of a severity in the HTML report
+func severityClass(severity auditor.Severity) string {
+	switch severity {
+	case auditor.SeverityCritical, auditor.SeverityHigh, auditor.SeverityMedium, auditor.SeverityLow:
+		return strings.ToLower(string(severity))
+	default:
+		return "info"
+	}
+}
+
+// severityOrder returns the severities counted in a summary, from the highest
+// to the lowest
+func severityOrder(bySeverity map[auditor.Severity]int) []auditor.Severity {
+	severities := make([]auditor.Severity, 0, len(bySeverity))
+	for severity := range bySeverity {
+		severities = append(severities, severity)
 	}
+	sort.Slice(severities, func(i, j int) bool {
+		if c := severities[i].Compare(severities[j]); c != 0 {
+			return c > 0
+		}
+		return severities[i] < severities[j]
+	})
+	return severities
 }
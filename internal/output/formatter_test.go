@@ -1,6 +1,7 @@
 package output
 
 import (
+	"strings"
 	"testing"
 	
 	"github.com/vibhordubey333/k8s-devguardian-ai/internal/ai"
@@ -85,6 +86,13 @@ func TestGenerateSummary(t *testing.T) {
 	if summary.ByResource["Service"] != 1 {
 		t.Errorf("Expected 1 Service finding, got %d", summary.ByResource["Service"])
 	}
+
+	// Suppressed findings are only counted as suppressed
+	findings = append(findings, auditor.AuditFinding{Resource: "Pod", Name: "cni", Severity: "Critical", Suppressed: true})
+	summary = GenerateSummary(findings)
+	if summary.TotalFindings != 3 || summary.BySeverity["Critical"] != 1 || summary.ByResource["Pod"] != 2 || summary.Suppressed != 1 {
+		t.Errorf("Expected the suppressed finding to be counted apart, got %+v", summary)
+	}
 }
 
 func TestJSONFormatter_Format(t *testing.T) {
@@ -118,7 +126,7 @@ func TestJSONFormatter_Format(t *testing.T) {
 		},
 		Summary: AuditSummary{
 			TotalFindings: 1,
-			BySeverity:    map[string]int{"Critical": 1},
+			BySeverity:    map[auditor.Severity]int{"Critical": 1},
 			ByResource:    map[string]int{"Pod": 1},
 		},
 	}
@@ -136,3 +144,62 @@ func TestJSONFormatter_Format(t *testing.T) {
 		t.Errorf("Expected non-empty output")
 	}
 }
+
+func TestFormatters_RuleAndFingerprint(t *testing.T) {
+	finding := auditor.AuditFinding{
+		Resource:    "Pod",
+		Namespace:   "default",
+		Name:        "test-pod",
+		Container:   "test-container",
+		Reason:      "Container 'test-container' is privileged",
+		Severity:    "Critical",
+		RuleID:      "DG-POD-002",
+		Title:       "Privileged container",
+		Fingerprint: "0123456789abcdef0123456789abcdef",
+	}
+	result := AuditResult{
+		Findings:     []auditor.AuditFinding{finding},
+		Explanations: []ai.FindingExplanation{{Finding: finding, Explanation: "Test explanation"}},
+		Summary:      GenerateSummary([]auditor.AuditFinding{finding}),
+	}
+
+	for _, format := range []Format{FormatCLI, FormatJSON, FormatHTML} {
+		out, err := NewFormatter(format).Format(result)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", format, err)
+		}
+		for _, want := range []string{"DG-POD-002", finding.Fingerprint, "test-container"} {
+			if !strings.Contains(string(out), want) {
+				t.Errorf("%s: expected the output to contain %q", format, want)
+			}
+		}
+	}
+}
+
+func TestCLIFormatter_Baseline(t *testing.T) {
+	fixed := auditor.AuditFinding{Resource: "Pod", Namespace: "default", Name: "old", Reason: "Container 'app' is privileged"}
+	result := AuditResult{
+		Summary: AuditSummary{Baseline: 12},
+		Fixed:   []auditor.AuditFinding{fixed},
+	}
+	report, err := NewCLIFormatter().Format(result)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, expected := range []string{"Baseline: 12 known finding(s)", "FIXED SINCE BASELINE: 1", "Pod/default/old: Container 'app' is privileged"} {
+		if !strings.Contains(string(report), expected) {
+			t.Errorf("Expected the report to contain %q", expected)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, format := range Formats {
+		if got, err := ParseFormat(string(format)); err != nil || got != format {
+			t.Errorf("Expected %s to parse, got %q, %v", format, got, err)
+		}
+	}
+	if _, err := ParseFormat("jsno"); err == nil || !strings.Contains(err.Error(), `unknown format "jsno"`) {
+		t.Errorf("Expected an error for an unknown format, got %v", err)
+	}
+}
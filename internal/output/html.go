@@ -3,7 +3,6 @@ package output
 import (
 	"bytes"
 	"html/template"
-	"strings"
 	"time"
 )
 
@@ -19,36 +18,7 @@ func NewHTMLFormatter() *HTMLFormatter {
 func (f *HTMLFormatter) Format(result AuditResult) ([]byte, error) {
 	var buf bytes.Buffer
 
-	tmpl, err := template.New("report").Funcs(template.FuncMap{
-		"severityClass": func(severity string) string {
-			switch strings.ToLower(severity) {
-			case "critical":
-				return "critical"
-			case "high":
-				return "high"
-			case "medium":
-				return "medium"
-			case "low":
-				return "low"
-			default:
-				return "info"
-			}
-		},
-		"severityIcon": func(severity string) string {
-			switch strings.ToLower(severity) {
-			case "critical":
-				return "🔴"
-			case "high":
-				return "🟠"
-			case "medium":
-				return "🟡"
-			case "low":
-				return "🟢"
-			default:
-				return "⚪"
-			}
-		},
-	}).Parse(htmlTemplate)
+	tmpl, err := template.New("report").Funcs(templateFuncs()).Parse(htmlTemplate)
 
 	if err != nil {
 		return nil, err
@@ -77,7 +47,111 @@ const htmlTemplate = `<!DOCTYPE html>
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>Kubernetes Security Audit Report</title>
     <style>
-        body {
+` + htmlStyle + `    </style>
+</head>
+<body>
+    <header>
+        <h1>Kubernetes Security Audit Report</h1>
+        <p>Generated on: {{.Timestamp}}</p>
+    </header>
+
+    <section class="summary">
+        <div class="summary-box">
+            <h2>Summary</h2>
+            <p>Total Findings: <strong>{{.Result.Summary.TotalFindings}}</strong></p>
+            {{if .Result.Summary.Suppressed}}
+            <p>Suppressed: <strong>{{.Result.Summary.Suppressed}}</strong></p>
+            {{end}}
+            {{if .Result.Summary.Baseline}}
+            <p>Known from the baseline: <strong>{{.Result.Summary.Baseline}}</strong></p>
+            {{end}}
+            <h3>By Severity</h3>
+            <ul>
+                {{range $severity := severityOrder .Result.Summary.BySeverity}}
+                <li>{{severityIcon $severity}} {{$severity}}: {{index $.Result.Summary.BySeverity $severity}}</li>
+                {{end}}
+            </ul>
+        </div>
+        <div class="summary-box">
+            <h2>Resource Types</h2>
+            <ul>
+                {{range $resource, $count := .Result.Summary.ByResource}}
+                <li>{{$resource}}: {{$count}}</li>
+                {{end}}
+            </ul>
+        </div>
+    </section>
+
+    <h2>Detailed Findings</h2>
+
+    {{range $index, $explanation := .Result.Explanations}}
+    <div class="finding">
+        <div class="finding-header">
+            <h3>Finding #{{add $index 1}}: {{$explanation.Finding.Resource}}/{{$explanation.Finding.Namespace}}/{{$explanation.Finding.Name}}</h3>
+            <span class="severity {{severityClass $explanation.Finding.Severity}}">{{severityIcon $explanation.Finding.Severity}} {{$explanation.Finding.Severity}}</span>
+        </div>
+
+        {{if $explanation.Finding.RuleID}}
+        <div class="section">
+            <div class="section-title">Rule:</div>
+            <p>{{ruleLabel $explanation.Finding}}</p>
+        </div>
+        {{end}}
+
+        {{if $explanation.Finding.Container}}
+        <div class="section">
+            <div class="section-title">Container:</div>
+            <p>{{$explanation.Finding.Container}}</p>
+        </div>
+        {{end}}
+
+        <div class="section">
+            <div class="section-title">Issue:</div>
+            <p>{{$explanation.Finding.Reason}}</p>
+        </div>
+
+        <div class="section">
+            <div class="section-title">Explanation:</div>
+            <p>{{$explanation.Explanation}}</p>
+        </div>
+
+        <div class="section">
+            <div class="section-title">Remediation:</div>
+            <p>{{$explanation.Remediation}}</p>
+        </div>
+
+        <div class="section">
+            <div class="section-title">References:</div>
+            <ul class="references">
+                {{range $ref := $explanation.References}}
+                <li><a href="{{$ref}}" target="_blank">{{$ref}}</a></li>
+                {{end}}
+            </ul>
+        </div>
+
+        {{if $explanation.Finding.Fingerprint}}
+        <div class="fingerprint">Fingerprint: <code>{{$explanation.Finding.Fingerprint}}</code></div>
+        {{end}}
+    </div>
+    {{end}}
+
+    {{if .Result.Fixed}}
+    <h2>Fixed Since Baseline</h2>
+    <ul>
+        {{range $finding := .Result.Fixed}}
+        <li>{{$finding.Resource}}/{{$finding.Namespace}}/{{$finding.Name}}: {{$finding.Reason}}</li>
+        {{end}}
+    </ul>
+    {{end}}
+
+    <footer>
+        <p>Generated by K8s DevGuardian AI - An AI-powered Kubernetes security auditing tool</p>
+    </footer>
+</body>
+</html>`
+
+// htmlStyle is the stylesheet shared by the HTML report and diff
+const htmlStyle = `        body {
             font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif;
             line-height: 1.6;
             color: #333;
@@ -168,78 +242,14 @@ const htmlTemplate = `<!DOCTYPE html>
         .references a:hover {
             text-decoration: underline;
         }
+        .fingerprint {
+            color: #666;
+            font-size: 0.85em;
+        }
         footer {
             margin-top: 30px;
             text-align: center;
             color: #666;
             font-size: 0.9em;
         }
-    </style>
-</head>
-<body>
-    <header>
-        <h1>Kubernetes Security Audit Report</h1>
-        <p>Generated on: {{.Timestamp}}</p>
-    </header>
-
-    <section class="summary">
-        <div class="summary-box">
-            <h2>Summary</h2>
-            <p>Total Findings: <strong>{{.Result.Summary.TotalFindings}}</strong></p>
-            <h3>By Severity</h3>
-            <ul>
-                {{range $severity, $count := .Result.Summary.BySeverity}}
-                <li>{{severityIcon $severity}} {{$severity}}: {{$count}}</li>
-                {{end}}
-            </ul>
-        </div>
-        <div class="summary-box">
-            <h2>Resource Types</h2>
-            <ul>
-                {{range $resource, $count := .Result.Summary.ByResource}}
-                <li>{{$resource}}: {{$count}}</li>
-                {{end}}
-            </ul>
-        </div>
-    </section>
-
-    <h2>Detailed Findings</h2>
-
-    {{range $index, $explanation := .Result.Explanations}}
-    <div class="finding">
-        <div class="finding-header">
-            <h3>Finding #{{add $index 1}}: {{$explanation.Finding.Resource}}/{{$explanation.Finding.Namespace}}/{{$explanation.Finding.Name}}</h3>
-            <span class="severity {{severityClass $explanation.Finding.Severity}}">{{severityIcon $explanation.Finding.Severity}} {{$explanation.Finding.Severity}}</span>
-        </div>
-
-        <div class="section">
-            <div class="section-title">Issue:</div>
-            <p>{{$explanation.Finding.Reason}}</p>
-        </div>
-
-        <div class="section">
-            <div class="section-title">Explanation:</div>
-            <p>{{$explanation.Explanation}}</p>
-        </div>
-
-        <div class="section">
-            <div class="section-title">Remediation:</div>
-            <p>{{$explanation.Remediation}}</p>
-        </div>
-
-        <div class="section">
-            <div class="section-title">References:</div>
-            <ul class="references">
-                {{range $ref := $explanation.References}}
-                <li><a href="{{$ref}}" target="_blank">{{$ref}}</a></li>
-                {{end}}
-            </ul>
-        </div>
-    </div>
-    {{end}}
-
-    <footer>
-        <p>Generated by K8s DevGuardian AI - An AI-powered Kubernetes security auditing tool</p>
-    </footer>
-</body>
-</html>`
+`
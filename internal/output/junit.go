@@ -0,0 +1,159 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/ai"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+)
+
+// JUnitFormatter implements the Formatter interface for JUnit XML output, as
+// rendered by the test reports of Jenkins, GitLab and other CI systems
+type JUnitFormatter struct{}
+
+// NewJUnitFormatter creates a new JUnit formatter
+func NewJUnitFormatter() *JUnitFormatter {
+	return &JUnitFormatter{}
+}
+
+// The subset of the JUnit XML schema the formatter writes
+type (
+	junitTestSuites struct {
+		XMLName  xml.Name         `xml:"testsuites"`
+		Name     string           `xml:"name,attr"`
+		Tests    int              `xml:"tests,attr"`
+		Failures int              `xml:"failures,attr"`
+		Skipped  int              `xml:"skipped,attr"`
+		Suites   []junitTestSuite `xml:"testsuite"`
+	}
+	junitTestSuite struct {
+		Name      string          `xml:"name,attr"`
+		Tests     int             `xml:"tests,attr"`
+		Failures  int             `xml:"failures,attr"`
+		Skipped   int             `xml:"skipped,attr"`
+		TestCases []junitTestCase `xml:"testcase"`
+	}
+	junitTestCase struct {
+		Name      string        `xml:"name,attr"`
+		ClassName string        `xml:"classname,attr"`
+		Failure   *junitFailure `xml:"failure,omitempty"`
+		Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	}
+	junitFailure struct {
+		Message string `xml:"message,attr"`
+		Type    string `xml:"type,attr"`
+		Text    string `xml:",chardata"`
+	}
+	junitSkipped struct {
+		Message string `xml:"message,attr"`
+	}
+)
+
+// junitCase is a rule checked on a resource, with the findings of the rule on
+// the resource
+type junitCase struct {
+	rule     string
+	resource string
+	findings []auditor.AuditFinding
+}
+
+// Format formats the audit result as JUnit XML with a test suite per rule and
+// a test case per resource the rule found issues on. A test case fails with
+// the reasons and the remediation of its findings, and is skipped with the
+// justification when all of its findings are suppressed.
+func (f *JUnitFormatter) Format(result AuditResult) ([]byte, error) {
+	explanations := make(map[auditor.AuditFinding]ai.FindingExplanation, len(result.Explanations))
+	for _, exp := range result.Explanations {
+		explanations[exp.Finding] = exp
+	}
+
+	// Group the findings by rule and resource, in rule and resource order
+	cases := make(map[string]*junitCase)
+	var keys []string
+	for _, finding := range result.Findings {
+		c := &junitCase{rule: sarifRuleID(finding), resource: resourceLabel(finding)}
+		key := c.rule + "\x00" + c.resource
+		if existing, ok := cases[key]; ok {
+			c = existing
+		} else {
+			cases[key] = c
+			keys = append(keys, key)
+		}
+		c.findings = append(c.findings, finding)
+	}
+	sort.Strings(keys)
+
+	suites := junitTestSuites{Name: "DevGuardian"}
+	rule := ""
+	for i, key := range keys {
+		c := cases[key]
+		if i == 0 || c.rule != rule {
+			suites.Suites = append(suites.Suites, junitTestSuite{Name: junitSuiteName(c)})
+			rule = c.rule
+		}
+		suite := &suites.Suites[len(suites.Suites)-1]
+
+		testCase := junitTestCase{Name: c.resource, ClassName: c.rule}
+		if active := auditor.Unsuppressed(c.findings); len(active) > 0 {
+			testCase.Failure = newJUnitFailure(active, explanations)
+			suite.Failures++
+		} else {
+			testCase.Skipped = &junitSkipped{Message: "Suppressed: " + c.findings[0].Justification}
+			suite.Skipped++
+		}
+		suite.Tests++
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	for _, suite := range suites.Suites {
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+	}
+
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// junitSuiteName returns the name of the test suite of a rule, such as
+// "DG-POD-002 Privileged container"
+func junitSuiteName(c *junitCase) string {
+	if title := c.findings[0].Title; title != "" {
+		return c.rule + " " + title
+	}
+	return c.rule
+}
+
+// newJUnitFailure returns the failure of a test case with unsuppressed
+// findings. The message is the reason of the first finding, and the text lists
+// the reasons, followed by the remediation of each explained finding.
+func newJUnitFailure(findings []auditor.AuditFinding, explanations map[auditor.AuditFinding]ai.FindingExplanation) *junitFailure {
+	var text strings.Builder
+	severity := findings[0].Severity
+	for _, finding := range findings {
+		if finding.Severity.Compare(severity) > 0 {
+			severity = finding.Severity
+		}
+		text.WriteString(fmt.Sprintf("[%s] %s\n", finding.Severity, finding.Reason))
+	}
+	for _, finding := range findings {
+		exp, ok := explanations[finding]
+		if !ok || exp.Remediation == "" {
+			continue
+		}
+		text.WriteString("\nRemediation: " + exp.Remediation + "\n")
+		for _, ref := range exp.References {
+			text.WriteString("  " + ref + "\n")
+		}
+	}
+	return &junitFailure{
+		Message: findings[0].Reason,
+		Type:    string(severity),
+		Text:    text.String(),
+	}
+}
@@ -0,0 +1,75 @@
+package output
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/ai"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+)
+
+func TestJUnitFormatter_Format(t *testing.T) {
+	app := auditor.AuditFinding{
+		Resource: "Pod", Namespace: "default", Name: "web", Container: "app",
+		Reason: "Container 'app' is privileged", Severity: auditor.SeverityCritical,
+		RuleID: "DG-POD-002", Title: "Privileged container",
+	}
+	sidecar := app
+	sidecar.Container, sidecar.Reason = "sidecar", "Container 'sidecar' is privileged"
+	agent := auditor.AuditFinding{
+		Resource: "Pod", Namespace: "kube-system", Name: "calico-node", Reason: "Container 'calico' is privileged",
+		Severity: auditor.SeverityCritical, RuleID: "DG-POD-002", Title: "Privileged container",
+		Suppressed: true, Justification: "The CNI must be privileged",
+	}
+	nodePort := auditor.AuditFinding{
+		Resource: "Service", Namespace: "default", Name: "web", Reason: "Service uses NodePort which exposes ports on all nodes",
+		Severity: auditor.SeverityMedium, RuleID: "DG-SVC-001", Title: "NodePort service",
+	}
+	result := AuditResult{
+		Findings: []auditor.AuditFinding{nodePort, app, agent, sidecar},
+		Explanations: []ai.FindingExplanation{{
+			Finding:     app,
+			Remediation: "Remove privileged: true.",
+			References:  []string{"https://kubernetes.io/docs/concepts/security/pod-security-standards/"},
+		}},
+	}
+
+	data, err := NewFormatter(FormatJUnit).Format(result)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(string(data), xml.Header) {
+		t.Errorf("Expected an XML header, got %q", data[:40])
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(data, &suites); err != nil {
+		t.Fatalf("Expected valid XML, got %v", err)
+	}
+	if suites.Tests != 3 || suites.Failures != 2 || suites.Skipped != 1 || len(suites.Suites) != 2 {
+		t.Fatalf("Expected 3 test cases in 2 suites, got %+v", suites)
+	}
+
+	pod := suites.Suites[0]
+	if pod.Name != "DG-POD-002 Privileged container" || pod.Tests != 2 || pod.Failures != 1 || pod.Skipped != 1 {
+		t.Fatalf("Unexpected suite %+v", pod)
+	}
+	web := pod.TestCases[0]
+	if web.Name != "Pod/default/web" || web.ClassName != "DG-POD-002" || web.Failure == nil ||
+		web.Failure.Message != app.Reason || web.Failure.Type != "Critical" {
+		t.Fatalf("Expected a failure for the privileged containers, got %+v", web)
+	}
+	for _, want := range []string{sidecar.Reason, "Remediation: Remove privileged: true.", "pod-security-standards"} {
+		if !strings.Contains(web.Failure.Text, want) {
+			t.Errorf("Expected the failure to contain %q, got %q", want, web.Failure.Text)
+		}
+	}
+	calico := pod.TestCases[1]
+	if calico.Name != "Pod/kube-system/calico-node" || calico.Failure != nil || calico.Skipped == nil ||
+		calico.Skipped.Message != "Suppressed: The CNI must be privileged" {
+		t.Errorf("Expected the suppressed finding to be skipped, got %+v", calico)
+	}
+	if svc := suites.Suites[1]; svc.Name != "DG-SVC-001 NodePort service" || svc.Failures != 1 || svc.TestCases[0].Failure.Type != "Medium" {
+		t.Errorf("Unexpected suite %+v", svc)
+	}
+}
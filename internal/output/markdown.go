@@ -0,0 +1,205 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/ai"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+)
+
+// MarkdownFormatter formats reports as GitHub flavored Markdown, such as for
+// pull request comments
+type MarkdownFormatter struct {
+	// MaxLength is the maximum length of an audit report in bytes, such as
+	// 65536 for GitHub comments. Findings that do not fit are listed without
+	// their explanation, then left out. 0 means no limit.
+	MaxLength int
+}
+
+// NewMarkdownFormatter creates a new Markdown formatter
+func NewMarkdownFormatter() *MarkdownFormatter {
+	return &MarkdownFormatter{}
+}
+
+// Format formats the audit result as Markdown, with a summary table by
+// severity followed by a collapsible section per finding with its explanation
+// and remediation. When the report would exceed MaxLength, the findings that
+// do not fit are listed on a line each, and those that still do not fit are
+// counted in a closing note.
+func (f *MarkdownFormatter) Format(result AuditResult) ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteString("# Kubernetes Security Audit Results\n\n")
+	buf.WriteString(fmt.Sprintf("**%d security issue(s) found**\n\n", result.Summary.TotalFindings))
+	if len(result.Summary.BySeverity) > 0 {
+		buf.WriteString("| Severity | Findings |\n|----------|----------|\n")
+		for _, severity := range severityOrder(result.Summary.BySeverity) {
+			buf.WriteString(fmt.Sprintf("| %s %s | %d |\n", severityIcon(severity), severity, result.Summary.BySeverity[severity]))
+		}
+		buf.WriteString("\n")
+	}
+	if result.Summary.Suppressed > 0 {
+		buf.WriteString(fmt.Sprintf("🔕 %d suppressed finding(s) are accepted risks and not listed.\n\n", result.Summary.Suppressed))
+	}
+	if result.Summary.Baseline > 0 {
+		buf.WriteString(fmt.Sprintf("📌 %d finding(s) are in the baseline and not listed.\n\n", result.Summary.Baseline))
+	}
+
+	var fixed bytes.Buffer
+	if len(result.Fixed) > 0 {
+		fixed.WriteString(fmt.Sprintf("## ✅ Fixed Since Baseline (%d)\n\n", len(result.Fixed)))
+		for _, finding := range result.Fixed {
+			fixed.WriteString("- " + markdownFindingLine(finding) + "\n")
+		}
+		fixed.WriteString("\n")
+		if f.MaxLength > 0 && buf.Len()+fixed.Len() > f.MaxLength {
+			fixed.Reset()
+			fixed.WriteString(fmt.Sprintf("## ✅ Fixed Since Baseline (%d)\n\nToo many to list here, see the full report.\n\n", len(result.Fixed)))
+		}
+	}
+
+	if len(result.Explanations) > 0 {
+		buf.WriteString("## Findings\n\n")
+	}
+	// Findings are written in full while they fit, then on a line each, always
+	// leaving room for the fixed findings and the truncation note
+	remaining := len(result.Explanations)
+	fits := func(s string) bool {
+		if f.MaxLength <= 0 {
+			return true
+		}
+		note := markdownTruncationNote(remaining)
+		return buf.Len()+len(s)+fixed.Len()+len(note) <= f.MaxLength
+	}
+	compact := false
+	for _, exp := range result.Explanations {
+		if !compact {
+			if details := markdownDetails(exp); fits(details) {
+				buf.WriteString(details)
+				remaining--
+				continue
+			}
+			compact = true
+		}
+		line := "- " + markdownFindingLine(exp.Finding) + "\n"
+		if !fits(line) {
+			break
+		}
+		buf.WriteString(line)
+		remaining--
+	}
+	if compact {
+		buf.WriteString("\n")
+	}
+	if remaining > 0 {
+		buf.WriteString(markdownTruncationNote(remaining))
+	}
+	buf.Write(fixed.Bytes())
+
+	return buf.Bytes(), nil
+}
+
+// markdownDetails returns the collapsible section of an explained finding
+func markdownDetails(exp ai.FindingExplanation) string {
+	var b strings.Builder
+	finding := exp.Finding
+	b.WriteString("<details>\n<summary>" + html.EscapeString(markdownFindingLine(finding)) + "</summary>\n\n")
+	if finding.RuleID != "" {
+		b.WriteString(fmt.Sprintf("- **Rule:** %s\n", ruleLabel(finding)))
+	}
+	b.WriteString(fmt.Sprintf("- **Resource:** `%s`\n", resourceLabel(finding)))
+	if finding.Container != "" {
+		b.WriteString(fmt.Sprintf("- **Container:** `%s`\n", finding.Container))
+	}
+	if finding.File != "" {
+		b.WriteString(fmt.Sprintf("- **Manifest:** `%s:%d`\n", finding.File, finding.Line))
+	}
+	if finding.Fingerprint != "" {
+		b.WriteString(fmt.Sprintf("- **Fingerprint:** `%s`\n", finding.Fingerprint))
+	}
+	if exp.Explanation != "" {
+		b.WriteString("\n**Explanation**\n\n" + exp.Explanation + "\n")
+	}
+	if exp.Remediation != "" {
+		b.WriteString("\n**Remediation**\n\n" + exp.Remediation + "\n")
+	}
+	if len(exp.References) > 0 {
+		b.WriteString("\n**References**\n\n")
+		for _, ref := range exp.References {
+			b.WriteString("- " + ref + "\n")
+		}
+	}
+	b.WriteString("\n</details>\n\n")
+	return b.String()
+}
+
+// markdownFindingLine describes a finding on a line, such as
+// "🔴 Critical DG-POD-002 Pod/default/web: Container 'app' is privileged"
+func markdownFindingLine(finding auditor.AuditFinding) string {
+	rule := ""
+	if finding.RuleID != "" {
+		rule = finding.RuleID + " "
+	}
+	reason := strings.ReplaceAll(strings.ReplaceAll(finding.Reason, "\r\n", " "), "\n", " ")
+	return fmt.Sprintf("%s %s %s%s: %s", severityIcon(finding.Severity), finding.Severity, rule, resourceLabel(finding), reason)
+}
+
+// markdownTruncationNote returns the note closing a report that leaves out
+// findings to stay under its maximum length
+func markdownTruncationNote(omitted int) string {
+	return fmt.Sprintf("> ⚠️ The report was truncated to stay under its maximum length: %d more finding(s) are not shown. See the full report for them.\n\n", omitted)
+}
+
+// FormatDiff formats the diff as Markdown, with a summary table followed by a
+// table for each kind of change
+func (f *MarkdownFormatter) FormatDiff(diff Diff) ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteString("# Kubernetes Security Audit Diff\n\n")
+	buf.WriteString(fmt.Sprintf("`%s` → `%s`\n\n", diff.OldReport, diff.NewReport))
+	buf.WriteString("| Change | Findings |\n|--------|----------|\n")
+	buf.WriteString(fmt.Sprintf("| 🆕 New | %d |\n", diff.Summary.New))
+	buf.WriteString(fmt.Sprintf("| ✅ Resolved | %d |\n", diff.Summary.Resolved))
+	buf.WriteString(fmt.Sprintf("| 🔀 Severity changed | %d |\n", diff.Summary.SeverityChanged))
+	buf.WriteString(fmt.Sprintf("| ➖ Unchanged | %d |\n", diff.Summary.Unchanged))
+
+	writeFindings := func(title string, findings []auditor.AuditFinding) {
+		if len(findings) == 0 {
+			return
+		}
+		buf.WriteString(fmt.Sprintf("\n## %s\n\n", title))
+		buf.WriteString("| Severity | Resource | Rule | Issue |\n|----------|----------|------|-------|\n")
+		for _, finding := range findings {
+			buf.WriteString(fmt.Sprintf("| %s %s | %s | %s | %s |\n", severityIcon(finding.Severity), finding.Severity,
+				markdownCell(resourceLabel(finding)), markdownCell(finding.RuleID), markdownCell(finding.Reason)))
+		}
+	}
+	writeFindings("New Findings", diff.New)
+	writeFindings("Resolved Findings", diff.Resolved)
+	if len(diff.SeverityChanged) > 0 {
+		buf.WriteString("\n## Severity Changed\n\n")
+		buf.WriteString("| Severity | Resource | Rule | Issue |\n|----------|----------|------|-------|\n")
+		for _, change := range diff.SeverityChanged {
+			finding := change.Finding
+			buf.WriteString(fmt.Sprintf("| %s → %s %s | %s | %s | %s |\n", change.OldSeverity, severityIcon(finding.Severity), finding.Severity,
+				markdownCell(resourceLabel(finding)), markdownCell(finding.RuleID), markdownCell(finding.Reason)))
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// resourceLabel returns the kind, namespace and name of the resource of a
+// finding, such as Pod/default/web
+func resourceLabel(finding auditor.AuditFinding) string {
+	return fmt.Sprintf("%s/%s/%s", finding.Resource, finding.Namespace, finding.Name)
+}
+
+// markdownCell escapes text for a Markdown table cell
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", " "), "\n", " ")
+}
@@ -0,0 +1,88 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/ai"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+)
+
+// markdownResult returns an audit result with n explained privileged pods
+func markdownResult(n int) AuditResult {
+	var result AuditResult
+	for i := 0; i < n; i++ {
+		finding := auditor.AuditFinding{
+			Resource: "Pod", Namespace: "default", Name: fmt.Sprintf("web-%d", i), Container: "app",
+			Reason: "Container 'app' is privileged | <root>", Severity: auditor.SeverityCritical,
+			RuleID: "DG-POD-002", Title: "Privileged container",
+		}
+		result.Findings = append(result.Findings, finding)
+		result.Explanations = append(result.Explanations, ai.FindingExplanation{
+			Finding:     finding,
+			Explanation: strings.Repeat("Privileged containers have full access to the host. ", 10),
+			Remediation: "Remove privileged: true.",
+			References:  []string{"https://kubernetes.io/docs/concepts/security/pod-security-standards/"},
+		})
+	}
+	result.Findings = append(result.Findings, auditor.AuditFinding{Resource: "Service", Severity: auditor.SeverityMedium, Suppressed: true})
+	result.Summary = GenerateSummary(result.Findings)
+	return result
+}
+
+func TestMarkdownFormatter_Format(t *testing.T) {
+	result := markdownResult(2)
+	result.Fixed = []auditor.AuditFinding{{Resource: "Pod", Namespace: "default", Name: "old", Reason: "Fixed", Severity: auditor.SeverityHigh}}
+
+	data, err := NewFormatter(FormatMarkdown).Format(result)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	report := string(data)
+	for _, want := range []string{
+		"| 🔴 Critical | 2 |",
+		"🔕 1 suppressed finding(s)",
+		"<summary>🔴 Critical DG-POD-002 Pod/default/web-0: Container &#39;app&#39; is privileged | &lt;root&gt;</summary>",
+		"- **Rule:** DG-POD-002 (Privileged container)",
+		"**Remediation**\n\nRemove privileged: true.",
+		"## ✅ Fixed Since Baseline (1)",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected the report to contain %q, got:\n%s", want, report)
+		}
+	}
+	if strings.Count(report, "<details>") != 2 || strings.Contains(report, "truncated") {
+		t.Errorf("Expected every finding in full, got:\n%s", report)
+	}
+}
+
+func TestMarkdownFormatter_MaxLength(t *testing.T) {
+	result := markdownResult(200)
+	full, _ := (&MarkdownFormatter{}).Format(result)
+
+	for _, maxLength := range []int{len(full) / 2, 2000, 500} {
+		data, err := (&MarkdownFormatter{MaxLength: maxLength}).Format(result)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		report := string(data)
+		if len(report) > maxLength {
+			t.Errorf("Expected at most %d bytes, got %d", maxLength, len(report))
+		}
+		details := strings.Count(report, "<details>")
+		lines := strings.Count(report, "\n- 🔴 Critical")
+		want := fmt.Sprintf("%d more finding(s) are not shown", 200-details-lines)
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected the report of %d bytes to note %q, got:\n%s", maxLength, want, report)
+		}
+	}
+
+	half, _ := (&MarkdownFormatter{MaxLength: len(full) / 2}).Format(result)
+	if strings.Count(string(half), "<details>") == 0 || strings.Count(string(half), "\n- 🔴 Critical") == 0 {
+		t.Errorf("Expected findings in full and on a line each, got:\n%s", half)
+	}
+	if fits, _ := (&MarkdownFormatter{MaxLength: len(full) + 1000}).Format(result); string(fits) != string(full) {
+		t.Error("Expected a report under the maximum length not to be truncated")
+	}
+}
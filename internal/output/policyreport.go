@@ -0,0 +1,36 @@
+package output
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/policyreport"
+	"sigs.k8s.io/yaml"
+)
+
+// PolicyReportFormatter implements the Formatter interface for
+// wgpolicyk8s.io/v1alpha2 PolicyReport and ClusterPolicyReport output
+type PolicyReportFormatter struct{}
+
+// NewPolicyReportFormatter creates a new PolicyReport formatter
+func NewPolicyReportFormatter() *PolicyReportFormatter {
+	return &PolicyReportFormatter{}
+}
+
+// Format formats the findings as a YAML stream of a PolicyReport per
+// namespace followed by a ClusterPolicyReport, which can be applied with
+// kubectl apply --server-side
+func (f *PolicyReportFormatter) Format(result AuditResult) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, report := range policyreport.Build(result.Findings, time.Now()) {
+		data, err := yaml.Marshal(report)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(data)
+	}
+	return buf.Bytes(), nil
+}
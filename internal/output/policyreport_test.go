@@ -0,0 +1,36 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/policyreport"
+	"sigs.k8s.io/yaml"
+)
+
+func TestPolicyReportFormatter_Format(t *testing.T) {
+	result := AuditResult{Findings: []auditor.AuditFinding{
+		{Resource: "Pod", Namespace: "default", Name: "web", Reason: "Container 'app' is privileged", Severity: auditor.SeverityCritical, RuleID: "DG-POD-002"},
+	}}
+
+	data, err := NewFormatter(FormatPolicyReport).Format(result)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	documents := strings.Split(string(data), "---\n")
+	if len(documents) != 2 {
+		t.Fatalf("Expected a PolicyReport and a ClusterPolicyReport, got:\n%s", data)
+	}
+	var report policyreport.Report
+	if err := yaml.Unmarshal([]byte(documents[0]), &report); err != nil {
+		t.Fatalf("Expected valid YAML, got %v", err)
+	}
+	if report.Kind != policyreport.KindPolicyReport || report.Namespace != "default" || report.Summary.Fail != 1 ||
+		report.Results[0].Policy != "DG-POD-002" {
+		t.Errorf("Unexpected report %+v", report)
+	}
+	if !strings.Contains(documents[1], "kind: ClusterPolicyReport") {
+		t.Errorf("Expected a ClusterPolicyReport, got:\n%s", documents[1])
+	}
+}
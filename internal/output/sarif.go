@@ -0,0 +1,271 @@
+package output
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/ai"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+)
+
+const (
+	// sarifSchema is the JSON schema of SARIF 2.1.0 logs
+	sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+	// sarifVersion is the SARIF version of the logs
+	sarifVersion = "2.1.0"
+	// sarifFingerprint is the key of the finding fingerprints in results
+	sarifFingerprint = "devguardian/v1"
+	// unidentifiedRule is the rule ID of findings of rules without an ID
+	unidentifiedRule = "DG-UNIDENTIFIED"
+)
+
+// SARIFFormatter implements the Formatter interface for SARIF 2.1.0 output,
+// as read by GitHub code scanning and other SARIF viewers
+type SARIFFormatter struct{}
+
+// NewSARIFFormatter creates a new SARIF formatter
+func NewSARIFFormatter() *SARIFFormatter {
+	return &SARIFFormatter{}
+}
+
+// The subset of the SARIF 2.1.0 object model the formatter writes
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID                   string              `json:"id"`
+		ShortDescription     sarifMessage        `json:"shortDescription"`
+		FullDescription      *sarifMessage       `json:"fullDescription,omitempty"`
+		Help                 *sarifMessage       `json:"help,omitempty"`
+		HelpURI              string              `json:"helpUri,omitempty"`
+		DefaultConfiguration sarifConfiguration  `json:"defaultConfiguration"`
+		Properties           sarifRuleProperties `json:"properties"`
+	}
+	sarifConfiguration struct {
+		Level string `json:"level"`
+	}
+	sarifRuleProperties struct {
+		Tags             []string `json:"tags"`
+		SecuritySeverity string   `json:"security-severity"`
+	}
+	sarifMessage struct {
+		Text     string `json:"text"`
+		Markdown string `json:"markdown,omitempty"`
+	}
+	sarifResult struct {
+		RuleID       string             `json:"ruleId"`
+		RuleIndex    int                `json:"ruleIndex"`
+		Level        string             `json:"level"`
+		Message      sarifMessage       `json:"message"`
+		Locations    []sarifLocation    `json:"locations"`
+		Fingerprints map[string]string  `json:"fingerprints,omitempty"`
+		Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+	}
+	sarifLocation struct {
+		PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+		LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+	sarifRegion struct {
+		StartLine int `json:"startLine"`
+	}
+	sarifLogicalLocation struct {
+		Name               string `json:"name"`
+		FullyQualifiedName string `json:"fullyQualifiedName"`
+		Kind               string `json:"kind"`
+	}
+	sarifSuppression struct {
+		Kind          string `json:"kind"`
+		Justification string `json:"justification,omitempty"`
+	}
+)
+
+// Format formats the audit result as a SARIF log with a single run. Every rule
+// with findings is described, using the explainer's explanation and
+// remediation as its description and help. Findings read from manifests are
+// located at their file and line, and the others at their resource.
+// Suppressed findings are included with their justification.
+func (f *SARIFFormatter) Format(result AuditResult) ([]byte, error) {
+	explanations := make(map[string]ai.FindingExplanation)
+	for _, exp := range result.Explanations {
+		id := sarifRuleID(exp.Finding)
+		if _, ok := explanations[id]; !ok {
+			explanations[id] = exp
+		}
+	}
+
+	// Describe each rule from its first finding, in ID order
+	first := make(map[string]auditor.AuditFinding)
+	var ids []string
+	for _, finding := range result.Findings {
+		id := sarifRuleID(finding)
+		if _, ok := first[id]; !ok {
+			first[id] = finding
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	rules := make([]sarifRule, len(ids))
+	ruleIndex := make(map[string]int, len(ids))
+	for i, id := range ids {
+		rules[i] = newSARIFRule(id, first[id], explanations[id])
+		ruleIndex[id] = i
+	}
+
+	results := make([]sarifResult, 0, len(result.Findings))
+	for _, finding := range result.Findings {
+		id := sarifRuleID(finding)
+		r := sarifResult{
+			RuleID:    id,
+			RuleIndex: ruleIndex[id],
+			Level:     sarifLevel(finding.Severity),
+			Message:   sarifMessage{Text: finding.Reason},
+			Locations: []sarifLocation{sarifResourceLocation(finding)},
+		}
+		if finding.Fingerprint != "" {
+			r.Fingerprints = map[string]string{sarifFingerprint: finding.Fingerprint}
+		}
+		if finding.Suppressed {
+			r.Suppressions = []sarifSuppression{{Kind: "external", Justification: finding.Justification}}
+		}
+		results = append(results, r)
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "DevGuardian",
+				InformationURI: "https://github.com/vibhordubey333/k8s-devguardian-ai",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+	return json.MarshalIndent(log, "", "  ")
+}
+
+// newSARIFRule describes a rule from one of its findings and the explanation
+// of its findings, if any
+func newSARIFRule(id string, finding auditor.AuditFinding, exp ai.FindingExplanation) sarifRule {
+	rule := sarifRule{
+		ID:                   id,
+		ShortDescription:     sarifMessage{Text: finding.Title},
+		DefaultConfiguration: sarifConfiguration{Level: sarifLevel(finding.Severity)},
+		Properties: sarifRuleProperties{
+			Tags:             []string{"security", "kubernetes"},
+			SecuritySeverity: securitySeverity(finding.Severity),
+		},
+	}
+	if rule.ShortDescription.Text == "" {
+		rule.ShortDescription.Text = id
+	}
+	if exp.Explanation != "" {
+		rule.FullDescription = &sarifMessage{Text: exp.Explanation}
+	}
+	if exp.Remediation != "" || len(exp.References) > 0 {
+		text, markdown := exp.Remediation, exp.Remediation
+		for _, ref := range exp.References {
+			text += "\n" + ref
+			markdown += "\n- " + ref
+		}
+		rule.Help = &sarifMessage{Text: strings.TrimSpace(text), Markdown: strings.TrimSpace(markdown)}
+	}
+	if len(exp.References) > 0 {
+		rule.HelpURI = exp.References[0]
+	}
+	return rule
+}
+
+// sarifRuleID returns the rule ID a finding is reported under
+func sarifRuleID(finding auditor.AuditFinding) string {
+	if finding.RuleID == "" {
+		return unidentifiedRule
+	}
+	return finding.RuleID
+}
+
+// sarifResourceLocation locates a finding at its manifest file and line, if
+// it was read from a manifest, and at its resource
+func sarifResourceLocation(finding auditor.AuditFinding) sarifLocation {
+	location := sarifLocation{
+		LogicalLocations: []sarifLogicalLocation{{
+			Name:               finding.Name,
+			FullyQualifiedName: resourceLabel(finding),
+			Kind:               "resource",
+		}},
+	}
+	if finding.File != "" && finding.File != "-" {
+		location.PhysicalLocation = &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: sarifURI(finding.File)}}
+		if finding.Line > 0 {
+			location.PhysicalLocation.Region = &sarifRegion{StartLine: finding.Line}
+		}
+	}
+	return location
+}
+
+// sarifURI returns the URI of a manifest file. Relative paths stay relative,
+// so that viewers resolve them against the repository root.
+func sarifURI(path string) string {
+	uri := filepath.ToSlash(filepath.Clean(path))
+	if filepath.IsAbs(path) {
+		if !strings.HasPrefix(uri, "/") {
+			uri = "/" + uri // Windows drive letters
+		}
+		return "file://" + uri
+	}
+	return uri
+}
+
+// sarifLevel maps a severity to a SARIF result level
+func sarifLevel(severity auditor.Severity) string {
+	switch severity {
+	case auditor.SeverityCritical, auditor.SeverityHigh:
+		return "error"
+	case auditor.SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// securitySeverity maps a severity to the CVSS-like score GitHub code scanning
+// uses to rank security alerts
+func securitySeverity(severity auditor.Severity) string {
+	switch severity {
+	case auditor.SeverityCritical:
+		return "9.5"
+	case auditor.SeverityHigh:
+		return "8.0"
+	case auditor.SeverityMedium:
+		return "5.5"
+	case auditor.SeverityLow:
+		return "3.0"
+	default:
+		return "0.0"
+	}
+}
@@ -0,0 +1,86 @@
+package output
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/ai"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+)
+
+func TestSARIFFormatter_Format(t *testing.T) {
+	privileged := auditor.AuditFinding{
+		Resource: "Pod", Namespace: "default", Name: "web", Container: "app",
+		Reason: "Container 'app' is privileged", Severity: auditor.SeverityCritical,
+		RuleID: "DG-POD-002", Title: "Privileged container", Fingerprint: "5d1c0f2b8e6a4c7f9b3e2d1a0c8f7e6d",
+		File: "deploy/web.yaml", Line: 12,
+	}
+	nodePort := auditor.AuditFinding{
+		Resource: "Service", Namespace: "default", Name: "web",
+		Reason: "Service uses NodePort which exposes ports on all nodes", Severity: auditor.SeverityMedium,
+		RuleID: "DG-SVC-001", Title: "NodePort service", Suppressed: true, Justification: "Exposed on purpose",
+	}
+	unidentified := auditor.AuditFinding{Resource: "Pod", Namespace: "default", Name: "web", Reason: "custom", Severity: auditor.SeverityLow}
+	result := AuditResult{
+		Findings: []auditor.AuditFinding{privileged, nodePort, unidentified},
+		Explanations: []ai.FindingExplanation{{
+			Finding:     privileged,
+			Explanation: "Privileged containers have full access to the host.",
+			Remediation: "Remove privileged: true.",
+			References:  []string{"https://kubernetes.io/docs/concepts/security/pod-security-standards/"},
+		}},
+	}
+
+	data, err := NewFormatter(FormatSARIF).Format(result)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("Unexpected log %+v", log)
+	}
+	run := log.Runs[0]
+
+	rules := run.Tool.Driver.Rules
+	if len(rules) != 3 || rules[0].ID != "DG-POD-002" || rules[1].ID != "DG-SVC-001" || rules[2].ID != unidentifiedRule {
+		t.Fatalf("Expected the rules sorted by ID, got %+v", rules)
+	}
+	pod := rules[0]
+	if pod.ShortDescription.Text != "Privileged container" || pod.FullDescription == nil ||
+		pod.FullDescription.Text != "Privileged containers have full access to the host." ||
+		pod.Help == nil || pod.Help.Text != "Remove privileged: true.\nhttps://kubernetes.io/docs/concepts/security/pod-security-standards/" ||
+		pod.HelpURI != "https://kubernetes.io/docs/concepts/security/pod-security-standards/" ||
+		pod.DefaultConfiguration.Level != "error" || pod.Properties.SecuritySeverity != "9.5" {
+		t.Errorf("Unexpected rule %+v", pod)
+	}
+	if rules[1].FullDescription != nil || rules[1].Help != nil {
+		t.Errorf("Expected no help for a rule without explanations, got %+v", rules[1])
+	}
+
+	if len(run.Results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(run.Results))
+	}
+	first := run.Results[0]
+	if first.RuleID != "DG-POD-002" || first.RuleIndex != 0 || first.Level != "error" || first.Message.Text != privileged.Reason ||
+		first.Fingerprints[sarifFingerprint] != privileged.Fingerprint {
+		t.Errorf("Unexpected result %+v", first)
+	}
+	location := first.Locations[0]
+	if location.PhysicalLocation == nil || location.PhysicalLocation.ArtifactLocation.URI != "deploy/web.yaml" ||
+		location.PhysicalLocation.Region == nil || location.PhysicalLocation.Region.StartLine != 12 ||
+		location.LogicalLocations[0].FullyQualifiedName != "Pod/default/web" {
+		t.Errorf("Unexpected location %+v", location)
+	}
+
+	second := run.Results[1]
+	if second.Level != "warning" || second.RuleIndex != 1 || second.Locations[0].PhysicalLocation != nil ||
+		len(second.Suppressions) != 1 || second.Suppressions[0].Justification != "Exposed on purpose" {
+		t.Errorf("Expected a suppressed result located at its resource, got %+v", second)
+	}
+	if third := run.Results[2]; third.RuleID != unidentifiedRule || third.Level != "note" || third.RuleIndex != 2 {
+		t.Errorf("Unexpected result for a finding without a rule ID: %+v", third)
+	}
+}
@@ -0,0 +1,122 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/ai"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+)
+
+// tableColumns are the columns of CSV and TSV reports, by name
+var tableColumns = map[string]func(auditor.AuditFinding, ai.FindingExplanation) string{
+	"rule_id":       func(f auditor.AuditFinding, _ ai.FindingExplanation) string { return f.RuleID },
+	"title":         func(f auditor.AuditFinding, _ ai.FindingExplanation) string { return f.Title },
+	"severity":      func(f auditor.AuditFinding, _ ai.FindingExplanation) string { return string(f.Severity) },
+	"kind":          func(f auditor.AuditFinding, _ ai.FindingExplanation) string { return f.Resource },
+	"namespace":     func(f auditor.AuditFinding, _ ai.FindingExplanation) string { return f.Namespace },
+	"name":          func(f auditor.AuditFinding, _ ai.FindingExplanation) string { return f.Name },
+	"container":     func(f auditor.AuditFinding, _ ai.FindingExplanation) string { return f.Container },
+	"cluster":       func(f auditor.AuditFinding, _ ai.FindingExplanation) string { return f.Cluster },
+	"reason":        func(f auditor.AuditFinding, _ ai.FindingExplanation) string { return f.Reason },
+	"explanation":   func(_ auditor.AuditFinding, e ai.FindingExplanation) string { return e.Explanation },
+	"remediation":   func(_ auditor.AuditFinding, e ai.FindingExplanation) string { return e.Remediation },
+	"references":    func(_ auditor.AuditFinding, e ai.FindingExplanation) string { return strings.Join(e.References, " ") },
+	"suppressed":    func(f auditor.AuditFinding, _ ai.FindingExplanation) string { return strconv.FormatBool(f.Suppressed) },
+	"justification": func(f auditor.AuditFinding, _ ai.FindingExplanation) string { return f.Justification },
+	"fingerprint":   func(f auditor.AuditFinding, _ ai.FindingExplanation) string { return f.Fingerprint },
+	"file":          func(f auditor.AuditFinding, _ ai.FindingExplanation) string { return f.File },
+	"line": func(f auditor.AuditFinding, _ ai.FindingExplanation) string {
+		if f.Line == 0 {
+			return ""
+		}
+		return strconv.Itoa(f.Line)
+	},
+}
+
+// DefaultColumns are the columns of CSV and TSV reports when none are chosen
+var DefaultColumns = []string{"rule_id", "severity", "kind", "namespace", "name", "container", "reason", "explanation", "remediation", "suppressed"}
+
+// TableFormatter implements the Formatter interface for CSV and TSV output,
+// with a header row followed by a row per finding
+type TableFormatter struct {
+	// Columns are the columns of the report, in order. DefaultColumns are used
+	// when it is empty.
+	Columns []string
+
+	tsv bool
+}
+
+// NewCSVFormatter creates a new formatter for RFC 4180 CSV output
+func NewCSVFormatter() *TableFormatter {
+	return &TableFormatter{}
+}
+
+// NewTSVFormatter creates a new formatter for tab-separated output
+func NewTSVFormatter() *TableFormatter {
+	return &TableFormatter{tsv: true}
+}
+
+// ValidateColumns returns an error naming the first unknown column, and the
+// known ones
+func ValidateColumns(columns []string) error {
+	for _, column := range columns {
+		if _, ok := tableColumns[column]; !ok {
+			names := make([]string, 0, len(tableColumns))
+			for name := range tableColumns {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return fmt.Errorf("unknown column %q (expected one of %s)", column, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// Format formats every finding, suppressed or not, as a row. CSV fields are
+// quoted as needed and rows end with CRLF, as in RFC 4180. TSV fields are
+// never quoted, so tabs and line breaks in them are replaced with spaces.
+func (f *TableFormatter) Format(result AuditResult) ([]byte, error) {
+	columns := f.Columns
+	if len(columns) == 0 {
+		columns = DefaultColumns
+	}
+	if err := ValidateColumns(columns); err != nil {
+		return nil, err
+	}
+
+	explanations := make(map[auditor.AuditFinding]ai.FindingExplanation, len(result.Explanations))
+	for _, exp := range result.Explanations {
+		explanations[exp.Finding] = exp
+	}
+	rows := [][]string{append([]string(nil), columns...)}
+	for _, finding := range result.Findings {
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = tableColumns[column](finding, explanations[finding])
+		}
+		rows = append(rows, row)
+	}
+
+	var buf bytes.Buffer
+	if f.tsv {
+		replacer := strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+		for _, row := range rows {
+			for i := range row {
+				row[i] = replacer.Replace(row[i])
+			}
+			buf.WriteString(strings.Join(row, "\t") + "\n")
+		}
+		return buf.Bytes(), nil
+	}
+	w := csv.NewWriter(&buf)
+	w.UseCRLF = true
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
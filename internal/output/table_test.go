@@ -0,0 +1,76 @@
+package output
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/ai"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+)
+
+// tableResult returns an audit result with an explained finding and a
+// suppressed one
+func tableResult() AuditResult {
+	privileged := auditor.AuditFinding{
+		Resource: "Pod", Namespace: "default", Name: "web", Container: "app",
+		Reason: "Container 'app' is privileged, \"really\"", Severity: auditor.SeverityCritical, RuleID: "DG-POD-002",
+	}
+	return AuditResult{
+		Findings: []auditor.AuditFinding{
+			privileged,
+			{Resource: "Service", Namespace: "default", Name: "web", Reason: "Service uses NodePort", Severity: auditor.SeverityMedium,
+				RuleID: "DG-SVC-001", Suppressed: true, Justification: "Exposed on purpose"},
+		},
+		Explanations: []ai.FindingExplanation{{
+			Finding:     privileged,
+			Explanation: "Privileged containers have full access to the host.\nAvoid them.",
+			Remediation: "Remove\tprivileged: true.",
+		}},
+	}
+}
+
+func TestTableFormatter_CSV(t *testing.T) {
+	data, err := NewFormatter(FormatCSV).Format(tableResult())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasSuffix(string(data), "\r\n") {
+		t.Error("Expected CRLF line endings")
+	}
+	rows, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected valid CSV, got %v", err)
+	}
+	if len(rows) != 3 || strings.Join(rows[0], ",") != strings.Join(DefaultColumns, ",") {
+		t.Fatalf("Expected a header and 2 rows, got %q", rows)
+	}
+	want := []string{"DG-POD-002", "Critical", "Pod", "default", "web", "app", "Container 'app' is privileged, \"really\"",
+		"Privileged containers have full access to the host.\nAvoid them.", "Remove\tprivileged: true.", "false"}
+	if strings.Join(rows[1], "|") != strings.Join(want, "|") {
+		t.Errorf("Expected %q, got %q", want, rows[1])
+	}
+	if rows[2][0] != "DG-SVC-001" || rows[2][7] != "" || rows[2][9] != "true" {
+		t.Errorf("Expected the suppressed finding without explanation, got %q", rows[2])
+	}
+}
+
+func TestTableFormatter_TSV(t *testing.T) {
+	formatter := NewTSVFormatter()
+	formatter.Columns = []string{"severity", "name", "remediation", "justification"}
+	data, err := formatter.Format(tableResult())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := "severity\tname\tremediation\tjustification\n" +
+		"Critical\tweb\tRemove privileged: true.\t\n" +
+		"Medium\tweb\t\tExposed on purpose\n"
+	if string(data) != want {
+		t.Errorf("Expected:\n%q\ngot:\n%q", want, data)
+	}
+
+	formatter.Columns = []string{"severity", "owner"}
+	if _, err := formatter.Format(tableResult()); err == nil || !strings.Contains(err.Error(), `unknown column "owner"`) {
+		t.Errorf("Expected an error for an unknown column, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"path/filepath"
+	"reflect"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// TemplateFormatter implements the Formatter interface for reports rendered
+// from a user-supplied Go template
+type TemplateFormatter struct {
+	// Path is the template file. Files ending in .html or .htm are rendered
+	// with html/template, which escapes the findings, and others with
+	// text/template.
+	Path string
+}
+
+// NewTemplateFormatter creates a new formatter rendering the template at path
+func NewTemplateFormatter(path string) *TemplateFormatter {
+	return &TemplateFormatter{Path: path}
+}
+
+// Format renders the template with the same data as the HTML report: the
+// audit result as .Result and the time of the report as .Timestamp. The
+// template can use the helpers of the HTML report and string helpers named
+// after those of Sprig.
+func (f *TemplateFormatter) Format(result AuditResult) ([]byte, error) {
+	if f.Path == "" {
+		return nil, fmt.Errorf("no template given (use --template)")
+	}
+
+	data := struct {
+		Result    AuditResult
+		Timestamp string
+	}{
+		Result:    result,
+		Timestamp: time.Now().Format("2006-01-02 15:04:05"),
+	}
+
+	var buf bytes.Buffer
+	name := filepath.Base(f.Path)
+	switch strings.ToLower(filepath.Ext(f.Path)) {
+	case ".html", ".htm":
+		tmpl, err := htmltemplate.New(name).Funcs(templateFuncs()).ParseFiles(f.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template: %w", err)
+		}
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to render template: %w", err)
+		}
+	default:
+		tmpl, err := texttemplate.New(name).Funcs(templateFuncs()).ParseFiles(f.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template: %w", err)
+		}
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to render template: %w", err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// templateFuncs returns the functions available to report templates: the
+// helpers of the HTML report, and string helpers named and ordered after those
+// of Sprig so that the value can be piped as the last argument
+func templateFuncs() map[string]interface{} {
+	return map[string]interface{}{
+		"severityClass": severityClass,
+		"severityIcon":  severityIcon,
+		"severityOrder": severityOrder,
+		"ruleLabel":     ruleLabel,
+		"resourceLabel": resourceLabel,
+		"add": func(a, b int) int {
+			return a + b
+		},
+
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"repeat":     func(count int, s string) string { return strings.Repeat(s, count) },
+		"join":       func(sep string, elems []string) string { return strings.Join(elems, sep) },
+		"split":      func(sep, s string) []string { return strings.Split(s, sep) },
+		"quote":      func(s string) string { return fmt.Sprintf("%q", s) },
+		"trunc": func(length int, s string) string {
+			if runes := []rune(s); len(runes) > length {
+				return string(runes[:length])
+			}
+			return s
+		},
+		"indent": func(spaces int, s string) string {
+			pad := strings.Repeat(" ", spaces)
+			return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+		},
+		"default": func(def, value interface{}) interface{} {
+			if v := reflect.ValueOf(value); !v.IsValid() || v.IsZero() {
+				return def
+			}
+			return value
+		},
+		"toJson": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		"now": time.Now,
+		"date": func(layout string, t time.Time) string {
+			return t.Format(layout)
+		},
+	}
+}
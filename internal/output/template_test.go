@@ -0,0 +1,73 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/ai"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+)
+
+// templateResult returns an audit result with two explained findings
+func templateResult() AuditResult {
+	var result AuditResult
+	for _, name := range []string{"web", "<api>"} {
+		finding := auditor.AuditFinding{
+			Resource: "Pod", Namespace: "default", Name: name, Reason: "Container 'app' is privileged",
+			Severity: auditor.SeverityCritical, RuleID: "DG-POD-002",
+		}
+		result.Findings = append(result.Findings, finding)
+		result.Explanations = append(result.Explanations, ai.FindingExplanation{Finding: finding, References: []string{"a", "b"}})
+	}
+	result.Summary = GenerateSummary(result.Findings)
+	return result
+}
+
+func TestTemplateFormatter_Format(t *testing.T) {
+	dir := t.TempDir()
+	text := filepath.Join(dir, "report.tmpl")
+	os.WriteFile(text, []byte(`{{range $i, $e := .Result.Explanations}}{{add $i 1}}. {{severityIcon $e.Finding.Severity}} {{upper $e.Finding.RuleID}} `+
+		`{{resourceLabel $e.Finding}} {{$e.Finding.Reason | replace "'" "" | trunc 13}} [{{join ", " $e.References}}] {{$e.Finding.Container | default "-"}}
+{{end}}`), 0644)
+
+	data, err := NewTemplateFormatter(text).Format(templateResult())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := "1. 🔴 DG-POD-002 Pod/default/web Container app [a, b] -\n2. 🔴 DG-POD-002 Pod/default/<api> Container app [a, b] -\n"
+	if string(data) != want {
+		t.Errorf("Expected:\n%q\ngot:\n%q", want, data)
+	}
+
+	// HTML templates escape the findings
+	html := filepath.Join(dir, "report.html")
+	os.WriteFile(html, []byte(`{{range .Result.Explanations}}<td class="{{severityClass .Finding.Severity}}">{{.Finding.Name}}</td>{{end}}`), 0644)
+	if data, err = NewTemplateFormatter(html).Format(templateResult()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(string(data), `<td class="critical">&lt;api&gt;</td>`) {
+		t.Errorf("Expected escaped HTML, got %s", data)
+	}
+
+	os.WriteFile(text, []byte(`{{.Result.Missing}}`), 0644)
+	if _, err := NewTemplateFormatter(text).Format(templateResult()); err == nil {
+		t.Error("Expected an error for an invalid template")
+	}
+	if _, err := NewFormatter(FormatTemplate).Format(templateResult()); err == nil {
+		t.Error("Expected an error without a template")
+	}
+}
+
+// The HTML report numbers the findings from 1 with the add helper
+func TestHTMLFormatter_FindingNumbers(t *testing.T) {
+	data, err := NewHTMLFormatter().Format(templateResult())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	report := string(data)
+	if !strings.Contains(report, "Finding #1:") || !strings.Contains(report, "Finding #2:") || strings.Contains(report, "Finding #0:") {
+		t.Errorf("Expected the findings to be numbered from 1, got:\n%s", report)
+	}
+}
@@ -0,0 +1,40 @@
+package devguardian.k8s
+
+# METADATA
+# title: Privileged pod exposed by a LoadBalancer
+# description: >-
+#   A LoadBalancer Service must not route traffic to privileged pods. Joins the
+#   Service against the pods in its namespace via data.inventory.
+# related_resources:
+# - ref: https://kubernetes.io/docs/concepts/services-networking/service/#loadbalancer
+#   description: LoadBalancer Services
+# custom:
+#   id: DG-SVC-003
+#   severity: critical
+deny[reason]{
+input.kind == "Service"
+input.spec.type == "LoadBalancer"
+pod := data.inventory.namespace[input.metadata.namespace]["v1"]["Pod"][_]
+selects(input.spec.selector, pod.metadata.labels)
+pod.spec.containers[_].securityContext.privileged == true
+reason := sprintf("LoadBalancer service %s exposes privileged pod %s", [input.metadata.name, pod.metadata.name])
+}
+
+selects(selector, labels){
+count(selector) > 0
+every_label_matches(selector, labels)
+}
+
+every_label_matches(selector, labels){
+not label_mismatch(selector, labels)
+}
+
+label_mismatch(selector, labels){
+value := selector[key]
+labels[key] != value
+}
+
+label_mismatch(selector, labels){
+selector[key]
+not labels[key]
+}
@@ -0,0 +1,29 @@
+package devguardian.k8s
+
+load_balancer = {
+"apiVersion": "v1",
+"kind": "Service",
+"metadata": {"name": "web", "namespace": "default"},
+"spec": {"type": "LoadBalancer", "selector": {"app": "web"}}
+}
+
+inventory_with(pod) = {"namespace": {"default": {"v1": {"Pod": {pod.metadata.name: pod}}}}}
+
+test_load_balancer_to_privileged_pod_denied{
+deny["LoadBalancer service web exposes privileged pod web"] with input as load_balancer with data.inventory as inventory_with(privileged_pod)
+}
+
+test_load_balancer_to_other_pods_allowed{
+pod := json.patch(privileged_pod, [{"op": "replace", "path": "/metadata/labels/app", "value": "db"}])
+count(deny) == 0 with input as load_balancer with data.inventory as inventory_with(pod)
+}
+
+test_cluster_ip_allowed{
+svc := json.patch(load_balancer, [{"op": "replace", "path": "/spec/type", "value": "ClusterIP"}])
+count(deny) == 0 with input as svc with data.inventory as inventory_with(privileged_pod)
+}
+
+test_load_balancer_to_differently_labelled_pods_allowed{
+pod := json.patch(privileged_pod, [{"op": "replace", "path": "/metadata/labels", "value": {"tier": "web"}}])
+count(deny) == 0 with input as load_balancer with data.inventory as inventory_with(pod)
+}
@@ -1,5 +1,16 @@
 package devguardian.k8s
 
+# METADATA
+# title: Privileged container
+# description: >-
+#   Privileged containers run with all capabilities and full access to the
+#   host's devices, so a compromised container compromises the node.
+# related_resources:
+# - ref: https://kubernetes.io/docs/concepts/security/pod-security-standards/#baseline
+#   description: Pod Security Standards (Baseline)
+# custom:
+#   id: DG-POD-004
+#   severity: high
 deny[reason]{
 input.kind == "Pod"
 input.spec.containers[_].securityContext.privileged == true
@@ -0,0 +1,17 @@
+package devguardian.k8s
+
+privileged_pod = {
+"apiVersion": "v1",
+"kind": "Pod",
+"metadata": {"name": "web", "namespace": "default", "labels": {"app": "web"}},
+"spec": {"containers": [{"name": "nginx", "securityContext": {"privileged": true}}]}
+}
+
+test_privileged_pod_denied{
+deny["Privileged container found in pod web"] with input as privileged_pod
+}
+
+test_unprivileged_pod_allowed{
+pod := json.patch(privileged_pod, [{"op": "replace", "path": "/spec/containers/0/securityContext/privileged", "value": false}])
+count(deny) == 0 with input as pod
+}
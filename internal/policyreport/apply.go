@@ -0,0 +1,74 @@
+package policyreport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// FieldManager is the field manager of the server-side applies
+const FieldManager = "devguardian"
+
+var (
+	// PolicyReportResource is the resource of PolicyReports
+	PolicyReportResource = schema.GroupVersionResource{Group: "wgpolicyk8s.io", Version: "v1alpha2", Resource: "policyreports"}
+	// ClusterPolicyReportResource is the resource of ClusterPolicyReports
+	ClusterPolicyReportResource = schema.GroupVersionResource{Group: "wgpolicyk8s.io", Version: "v1alpha2", Resource: "clusterpolicyreports"}
+)
+
+// Apply writes the reports to the cluster with server-side apply, taking over
+// the fields of other managers. With prune, the PolicyReports written by
+// earlier audits in namespaces that no longer have findings are deleted, so
+// that dashboards do not keep showing fixed findings. Prune only after a
+// complete scan, as the reports of the parts that were skipped would be lost.
+func Apply(ctx context.Context, client dynamic.Interface, reports []Report, prune bool) error {
+	written := make(map[string]bool)
+	for _, report := range reports {
+		data, err := json.Marshal(report)
+		if err != nil {
+			return err
+		}
+		var resource dynamic.ResourceInterface = client.Resource(ClusterPolicyReportResource)
+		if report.Namespace != "" {
+			resource = client.Resource(PolicyReportResource).Namespace(report.Namespace)
+		}
+		force := true
+		if _, err := resource.Patch(ctx, report.Name, types.ApplyPatchType, data, metav1.PatchOptions{
+			FieldManager: FieldManager,
+			Force:        &force,
+		}); err != nil {
+			if apierrors.IsNotFound(err) {
+				// Applying creates missing objects, so the resource itself is missing
+				return fmt.Errorf("failed to apply %s %s: %w (are the wgpolicyk8s.io CRDs installed?)", report.Kind, report.Name, err)
+			}
+			return fmt.Errorf("failed to apply %s %s/%s: %w", report.Kind, report.Namespace, report.Name, err)
+		}
+		written[report.Namespace] = true
+	}
+	if !prune {
+		return nil
+	}
+
+	list, err := client.Resource(PolicyReportResource).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: ManagedByLabel + "=" + Name,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list PolicyReports: %w", err)
+	}
+	for _, item := range list.Items {
+		if item.GetName() != Name || written[item.GetNamespace()] {
+			continue
+		}
+		err := client.Resource(PolicyReportResource).Namespace(item.GetNamespace()).Delete(ctx, item.GetName(), metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete PolicyReport %s/%s: %w", item.GetNamespace(), item.GetName(), err)
+		}
+	}
+	return nil
+}
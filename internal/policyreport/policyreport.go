@@ -0,0 +1,247 @@
+// Package policyreport converts findings to the PolicyReport and
+// ClusterPolicyReport resources of the Kubernetes Policy WG
+// (wgpolicyk8s.io/v1alpha2), as read by dashboards such as Policy Reporter
+package policyreport
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/suppression"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// APIVersion is the API version of the reports
+	APIVersion = "wgpolicyk8s.io/v1alpha2"
+	// KindPolicyReport is the kind of the report of a namespace
+	KindPolicyReport = "PolicyReport"
+	// KindClusterPolicyReport is the kind of the report of the cluster-scoped
+	// resources
+	KindClusterPolicyReport = "ClusterPolicyReport"
+	// Name is the name of the reports
+	Name = "devguardian"
+	// Source is the source recorded on the results
+	Source = "devguardian"
+	// ManagedByLabel marks the reports written by DevGuardian
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	// unidentifiedPolicy is the policy of findings of rules without an ID
+	unidentifiedPolicy = "DG-UNIDENTIFIED"
+)
+
+// Report is a PolicyReport, or a ClusterPolicyReport when it has no namespace
+type Report struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Summary           Summary  `json:"summary"`
+	Results           []Result `json:"results,omitempty"`
+}
+
+// Summary counts the results of a report by status
+type Summary struct {
+	Pass  int `json:"pass"`
+	Fail  int `json:"fail"`
+	Warn  int `json:"warn"`
+	Error int `json:"error"`
+	Skip  int `json:"skip"`
+}
+
+// Result is a finding in a report
+type Result struct {
+	Source     string            `json:"source"`
+	Policy     string            `json:"policy"`
+	Rule       string            `json:"rule,omitempty"`
+	Severity   string            `json:"severity,omitempty"`
+	Timestamp  Timestamp         `json:"timestamp"`
+	Result     string            `json:"result"`
+	Scored     bool              `json:"scored"`
+	Resources  []ObjectReference `json:"resources,omitempty"`
+	Message    string            `json:"message"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// Timestamp is the time a result was produced
+type Timestamp struct {
+	Seconds int64 `json:"seconds"`
+	Nanos   int32 `json:"nanos"`
+}
+
+// ObjectReference refers to the resource of a result
+type ObjectReference struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+// kinds are the API versions of the kinds findings are commonly reported on,
+// and whether they are namespaced
+var kinds = map[string]struct {
+	apiVersion string
+	namespaced bool
+}{
+	"Pod":                      {"v1", true},
+	"Service":                  {"v1", true},
+	"ServiceAccount":           {"v1", true},
+	"ConfigMap":                {"v1", true},
+	"Secret":                   {"v1", true},
+	"PersistentVolumeClaim":    {"v1", true},
+	"Namespace":                {"v1", false},
+	"Node":                     {"v1", false},
+	"PersistentVolume":         {"v1", false},
+	"Deployment":               {"apps/v1", true},
+	"DaemonSet":                {"apps/v1", true},
+	"StatefulSet":              {"apps/v1", true},
+	"ReplicaSet":               {"apps/v1", true},
+	"Job":                      {"batch/v1", true},
+	"CronJob":                  {"batch/v1", true},
+	"Ingress":                  {"networking.k8s.io/v1", true},
+	"NetworkPolicy":            {"networking.k8s.io/v1", true},
+	"Role":                     {"rbac.authorization.k8s.io/v1", true},
+	"RoleBinding":              {"rbac.authorization.k8s.io/v1", true},
+	"ClusterRole":              {"rbac.authorization.k8s.io/v1", false},
+	"ClusterRoleBinding":       {"rbac.authorization.k8s.io/v1", false},
+	"StorageClass":             {"storage.k8s.io/v1", false},
+	"CustomResourceDefinition": {"apiextensions.k8s.io/v1", false},
+}
+
+// Build converts findings to a PolicyReport for every namespace with findings,
+// in namespace order, followed by a ClusterPolicyReport for the cluster-scoped
+// resources. The ClusterPolicyReport is always returned, so that a clean audit
+// is reported too. Suppressed findings are skipped results with their
+// justification, and the others failed results. Findings on a Namespace are
+// reported in the ClusterPolicyReport, as Namespaces are cluster-scoped.
+func Build(findings []auditor.AuditFinding, now time.Time) []Report {
+	namespaces := make(map[string]*Report)
+	cluster := newReport("")
+	for _, finding := range findings {
+		report := &cluster
+		if namespace := reportNamespace(finding); namespace != "" {
+			if namespaces[namespace] == nil {
+				r := newReport(namespace)
+				namespaces[namespace] = &r
+			}
+			report = namespaces[namespace]
+		}
+		report.add(newResult(finding, now))
+	}
+
+	names := make([]string, 0, len(namespaces))
+	for namespace := range namespaces {
+		names = append(names, namespace)
+	}
+	sort.Strings(names)
+	reports := make([]Report, 0, len(names)+1)
+	for _, namespace := range names {
+		reports = append(reports, *namespaces[namespace])
+	}
+	return append(reports, cluster)
+}
+
+// newReport returns an empty report of a namespace, or of the cluster if the
+// namespace is empty
+func newReport(namespace string) Report {
+	kind := KindPolicyReport
+	if namespace == "" {
+		kind = KindClusterPolicyReport
+	}
+	return Report{
+		TypeMeta: metav1.TypeMeta{APIVersion: APIVersion, Kind: kind},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      Name,
+			Namespace: namespace,
+			Labels:    map[string]string{ManagedByLabel: Name},
+		},
+	}
+}
+
+// add adds a result to the report and counts it in the summary
+func (r *Report) add(result Result) {
+	r.Results = append(r.Results, result)
+	switch result.Result {
+	case "skip":
+		r.Summary.Skip++
+	default:
+		r.Summary.Fail++
+	}
+}
+
+// reportNamespace returns the namespace of the report of a finding, or an
+// empty string for the ClusterPolicyReport
+func reportNamespace(finding auditor.AuditFinding) string {
+	if kind, ok := kinds[finding.Resource]; ok && !kind.namespaced {
+		return ""
+	}
+	return finding.Namespace
+}
+
+// newResult converts a finding to a result
+func newResult(finding auditor.AuditFinding, now time.Time) Result {
+	result := Result{
+		Source:    Source,
+		Policy:    finding.RuleID,
+		Rule:      finding.Title,
+		Severity:  severity(finding.Severity),
+		Timestamp: Timestamp{Seconds: now.Unix(), Nanos: int32(now.Nanosecond())},
+		Result:    "fail",
+		Scored:    true,
+		Message:   finding.Reason,
+	}
+	if result.Policy == "" {
+		result.Policy = unidentifiedPolicy
+	}
+	// Expired suppressions are findings on the suppression file, not on a
+	// resource
+	if finding.RuleID != suppression.ExpiredRuleID {
+		ref := ObjectReference{Kind: finding.Resource, Name: finding.Name}
+		if kind, ok := kinds[finding.Resource]; ok {
+			ref.APIVersion = kind.apiVersion
+		}
+		if reportNamespace(finding) != "" {
+			ref.Namespace = finding.Namespace
+		}
+		result.Resources = []ObjectReference{ref}
+	}
+
+	properties := make(map[string]string)
+	for key, value := range map[string]string{
+		"fingerprint": finding.Fingerprint,
+		"container":   finding.Container,
+		"cluster":     finding.Cluster,
+		"file":        finding.File,
+	} {
+		if value != "" {
+			properties[key] = value
+		}
+	}
+	if finding.Line > 0 {
+		properties["line"] = strconv.Itoa(finding.Line)
+	}
+	if finding.Suppressed {
+		result.Result = "skip"
+		properties["justification"] = finding.Justification
+	}
+	if len(properties) > 0 {
+		result.Properties = properties
+	}
+	return result
+}
+
+// severity returns the result severity of a finding severity, which is
+// lowercase in PolicyReports
+func severity(s auditor.Severity) string {
+	switch s {
+	case auditor.SeverityCritical:
+		return "critical"
+	case auditor.SeverityHigh:
+		return "high"
+	case auditor.SeverityMedium:
+		return "medium"
+	case auditor.SeverityLow:
+		return "low"
+	default:
+		return "info"
+	}
+}
@@ -0,0 +1,137 @@
+package policyreport
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/suppression"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var findings = []auditor.AuditFinding{
+	{Resource: "Pod", Namespace: "web", Name: "api", Container: "app", Reason: "Container 'app' is privileged",
+		Severity: auditor.SeverityCritical, RuleID: "DG-POD-002", Title: "Privileged container", Fingerprint: "abc"},
+	{Resource: "Service", Namespace: "default", Name: "web", Reason: "Service uses NodePort",
+		Severity: auditor.SeverityMedium, RuleID: "DG-SVC-001", Suppressed: true, Justification: "Exposed on purpose"},
+	{Resource: "Namespace", Namespace: "web", Name: "web", Reason: "Namespace does not enforce PodSecurity standards",
+		Severity: auditor.SeverityHigh, RuleID: "DG-NS-001"},
+	{Resource: "Suppression", Name: ".devguardianignore#1", Reason: "Suppression expired",
+		Severity: auditor.SeverityMedium, RuleID: suppression.ExpiredRuleID},
+}
+
+func TestBuild(t *testing.T) {
+	now := time.Unix(1760745600, 5)
+	reports := Build(findings, now)
+	if len(reports) != 3 {
+		t.Fatalf("Expected 2 PolicyReports and a ClusterPolicyReport, got %+v", reports)
+	}
+
+	defaultReport, web, cluster := reports[0], reports[1], reports[2]
+	if defaultReport.Kind != KindPolicyReport || defaultReport.Namespace != "default" || defaultReport.Name != Name ||
+		defaultReport.APIVersion != APIVersion || defaultReport.Labels[ManagedByLabel] != Name {
+		t.Errorf("Unexpected report metadata %+v", defaultReport)
+	}
+	if defaultReport.Summary != (Summary{Skip: 1}) || defaultReport.Results[0].Result != "skip" ||
+		defaultReport.Results[0].Properties["justification"] != "Exposed on purpose" {
+		t.Errorf("Expected the suppressed finding to be skipped, got %+v", defaultReport)
+	}
+
+	if web.Summary != (Summary{Fail: 1}) || len(web.Results) != 1 {
+		t.Fatalf("Expected one failure in the web namespace, got %+v", web)
+	}
+	result := web.Results[0]
+	if result.Source != Source || result.Policy != "DG-POD-002" || result.Rule != "Privileged container" ||
+		result.Severity != "critical" || result.Result != "fail" || result.Message != "Container 'app' is privileged" ||
+		result.Timestamp != (Timestamp{Seconds: 1760745600, Nanos: 5}) ||
+		result.Properties["fingerprint"] != "abc" || result.Properties["container"] != "app" {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if len(result.Resources) != 1 || result.Resources[0] != (ObjectReference{APIVersion: "v1", Kind: "Pod", Namespace: "web", Name: "api"}) {
+		t.Errorf("Unexpected resources %+v", result.Resources)
+	}
+
+	if cluster.Kind != KindClusterPolicyReport || cluster.Namespace != "" || cluster.Summary != (Summary{Fail: 2}) {
+		t.Fatalf("Expected the Namespace and expired suppression in the ClusterPolicyReport, got %+v", cluster)
+	}
+	if ns := cluster.Results[0]; ns.Severity != "high" || len(ns.Resources) != 1 ||
+		ns.Resources[0] != (ObjectReference{APIVersion: "v1", Kind: "Namespace", Name: "web"}) {
+		t.Errorf("Unexpected Namespace result %+v", ns)
+	}
+	if expired := cluster.Results[1]; expired.Resources != nil {
+		t.Errorf("Expected no resource for an expired suppression, got %+v", expired.Resources)
+	}
+
+	if clean := Build(nil, now); len(clean) != 1 || clean[0].Kind != KindClusterPolicyReport || clean[0].Summary != (Summary{}) {
+		t.Errorf("Expected an empty ClusterPolicyReport for a clean audit, got %+v", clean)
+	}
+}
+
+// existingReport returns a report written by an earlier audit
+func existingReport(kind, namespace, name string) *unstructured.Unstructured {
+	report := &unstructured.Unstructured{}
+	report.SetAPIVersion(APIVersion)
+	report.SetKind(kind)
+	report.SetNamespace(namespace)
+	report.SetName(name)
+	report.SetLabels(map[string]string{ManagedByLabel: Name})
+	return report
+}
+
+func TestApply(t *testing.T) {
+	other := existingReport(KindPolicyReport, "fixed", "kyverno")
+	other.SetLabels(nil)
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		PolicyReportResource:        "PolicyReportList",
+		ClusterPolicyReportResource: "ClusterPolicyReportList",
+	}, existingReport(KindPolicyReport, "web", Name), existingReport(KindPolicyReport, "fixed", Name), other)
+
+	// The fake client cannot apply unstructured objects, so the applies are
+	// recorded instead
+	applied := make(map[string]Report)
+	client.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			t.Errorf("Expected a server-side apply, got %s", patch.GetPatchType())
+		}
+		var report Report
+		if err := json.Unmarshal(patch.GetPatch(), &report); err != nil {
+			t.Fatalf("Expected a report, got %v", err)
+		}
+		applied[patch.GetResource().Resource+"/"+patch.GetNamespace()+"/"+patch.GetName()] = report
+		return true, existingReport(report.Kind, report.Namespace, report.Name), nil
+	})
+
+	ctx := context.Background()
+	if err := Apply(ctx, client, Build(findings, time.Now()), false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(applied) != 3 || applied["policyreports/web/devguardian"].Summary.Fail != 1 ||
+		len(applied["clusterpolicyreports//devguardian"].Results) != 2 {
+		t.Errorf("Expected the reports to be applied, got %+v", applied)
+	}
+	if _, err := client.Resource(PolicyReportResource).Namespace("fixed").Get(ctx, Name, metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected no pruning without prune, got %v", err)
+	}
+
+	if err := Apply(ctx, client, Build(findings, time.Now()), true); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := client.Resource(PolicyReportResource).Namespace("fixed").Get(ctx, Name, metav1.GetOptions{}); err == nil {
+		t.Error("Expected the report of a namespace without findings to be pruned")
+	}
+	if _, err := client.Resource(PolicyReportResource).Namespace("web").Get(ctx, Name, metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected the report of a namespace with findings to be kept, got %v", err)
+	}
+	if _, err := client.Resource(PolicyReportResource).Namespace("fixed").Get(ctx, "kyverno", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected the reports of other tools to be kept, got %v", err)
+	}
+}
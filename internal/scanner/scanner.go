@@ -3,168 +3,559 @@ package scanner
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 	"path/filepath"
 	"os"
 
+	"github.com/open-policy-agent/opa/bundle"
 	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/kyverno"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/library"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/manifest"
 	"github.com/vibhordubey333/k8s-devguardian-ai/internal/opa"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/suppression"
+	"github.com/vibhordubey333/k8s-devguardian-ai/pkg/checks"
 
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/homedir"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
 	corev1 "k8s.io/api/core/v1"
-	"gopkg.in/yaml.v2"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 )
 
-// ScanCluster scans the Kubernetes cluster and returns findings
-func ScanCluster() ([]auditor.AuditFinding, error) {
-	var findings []auditor.AuditFinding
+// Engine selects the implementation of the built-in checks
+type Engine string
+
+const (
+	// EngineGo runs the built-in checks as Go code
+	EngineGo Engine = "go"
+	// EngineRego runs the built-in checks from the default Rego library
+	EngineRego Engine = "rego"
+)
+
+// Options configures a cluster scan
+type Options struct {
+	// PolicyPaths are the policy files or directories to evaluate. When empty
+	// the built-in policies are used, if present.
+	PolicyPaths []string
+	// PolicyBundles are OPA bundles (tarballs, directories or URLs) whose
+	// policies are evaluated alongside those in PolicyPaths
+	PolicyBundles []string
+	// BundleOptions configures bundle downloads and signature verification
+	BundleOptions opa.BundleOptions
+	// Engine runs the built-in checks, EngineGo if empty
+	Engine Engine
+	// EnableChecks selects the built-in checks to run by ID (wildcards
+	// allowed); all checks run if empty
+	EnableChecks []string
+	// DisableChecks excludes built-in checks by ID (wildcards allowed)
+	DisableChecks []string
+	// Wasm compiles the Rego policies to Wasm and evaluates them with the
+	// OPA Wasm runtime, which requires a build with the opa_wasm tag
+	Wasm bool
+	// Params are made available to the Rego policies as data.params
+	Params opa.Params
+	// Cluster is the cluster name recorded on the findings, and part of their
+	// fingerprints. Defaults to the cluster of the current kubeconfig context.
+	Cluster string
+	// Suppressions mark the findings they match as suppressed. Expired
+	// suppressions are reported as findings.
+	Suppressions *suppression.File
+	// DisableAnnotations ignores the devguardian.io/ignore annotations on the
+	// resources and their namespaces, which otherwise suppress findings
+	DisableAnnotations bool
+}
+
+// Resources are the objects collected from the cluster
+type Resources struct {
+	Pods            []corev1.Pod
+	Services        []corev1.Service
+	Roles           []rbacv1.Role
+	Namespaces      []corev1.Namespace
+	NetworkPolicies []networkingv1.NetworkPolicy
+}
+
+// ScanError records a part of the scan that failed, such as a resource kind
+// that could not be listed or an object a policy failed to evaluate
+type ScanError struct {
+	// Resource is what was not scanned, such as "pods" or "Pod default/web"
+	Resource string
+	Err      error
+}
 
-	kubeconfig := filepath.Join(homedir.HomeDir(), ".kube", "config")
+func (e ScanError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Resource, e.Err)
+}
+
+func (e ScanError) Unwrap() error {
+	return e.Err
+}
+
+// PartialError is returned by ScanCluster together with the findings when
+// parts of the cluster could not be scanned. The findings cover the rest of
+// the cluster.
+type PartialError struct {
+	Errors []ScanError
+}
+
+func (e *PartialError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("scan incomplete: %s", strings.Join(messages, "; "))
+}
+
+// ScanCluster scans the Kubernetes cluster and returns findings. When only
+// parts of the scan fail, such as a resource kind the user may not list, the
+// findings for the rest are returned with a *PartialError.
+func ScanCluster(options Options) ([]auditor.AuditFinding, error) {
+	// Load the policy bundles first so that an unavailable or tampered bundle
+	// fails the scan before the cluster is contacted
+	bundles, selected, err := loadPolicies(options)
+	if err != nil {
+		return nil, err
+	}
+
+	kubeconfig := kubeConfigPath()
 
 	config, err := loadKubeConfig(kubeconfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build kubeconfig: %w", err)
 	}
+	cluster := options.Cluster
+	if cluster == "" {
+		cluster = currentCluster(kubeconfig)
+	}
 
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create clientset: %w", err)
 	}
 
-	// Scan pods
-	fmt.Println("Scanning pods...")
-	pods, err := clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+	resources, listErrors := collectResources(clientset)
+	if len(listErrors) == len(resourceKinds) {
+		// Nothing could be listed, so the cluster is most likely unreachable
+		return nil, listErrors[0].Err
+	}
+	return scan(newInventory(resources), cluster, bundles, selected, options, listErrors)
+}
+
+// ScanManifests scans the objects in manifest files or directories instead of
+// a cluster, as ScanCluster does. Findings are located at the file and line
+// of the object's document. The cluster recorded on the findings is
+// options.Cluster.
+func ScanManifests(paths []string, options Options) ([]auditor.AuditFinding, error) {
+	bundles, selected, err := loadPolicies(options)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Println("Loading manifests...")
+	objects, err := manifest.Load(paths)
+	if err != nil {
+		return nil, err
+	}
+	inventory := opa.NewInventory()
+	locations := make(map[string]manifest.Object, len(objects))
+	for _, obj := range objects {
+		inventory.Add(obj.Object)
+		locations[objectKey(obj.Object)] = obj
+	}
+
+	findings, err := scan(inventory, options.Cluster, bundles, selected, options, nil)
+	for i := range findings {
+		f := &findings[i]
+		if obj, ok := locations[resourceKey(f.Resource, f.Namespace, f.Name)]; ok {
+			f.File, f.Line = obj.File, obj.Line
+		}
+	}
+	return findings, err
+}
+
+// loadPolicies loads the policy bundles of options, including the Rego
+// library with the Rego engine, and selects the built-in checks to run
+func loadPolicies(options Options) ([]*bundle.Bundle, []checks.Check, error) {
+	bundles, err := opa.LoadBundles(options.PolicyBundles, options.BundleOptions)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list pods: %w", err)
+		return nil, nil, err
 	}
 
-	// Audit pods using built-in checks
-	podFindings := auditor.AuditPodSecurity(pods.Items)
-	findings = append(findings, podFindings...)
+	if options.Wasm && !opa.WasmEnabled() {
+		return nil, nil, opa.ErrWasmUnsupported
+	}
 
-	// Scan pods with OPA policies
-	for _, pod := range pods.Items {
-		podYAML, err := yaml.Marshal(pod)
+	selected, err := checks.Default().Select(options.EnableChecks, options.DisableChecks)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch options.Engine {
+	case EngineGo, "":
+	case EngineRego:
+		lib, err := library.Bundle()
 		if err != nil {
-			fmt.Printf("Warning: Failed to marshal pod %s/%s: %v\n", pod.Namespace, pod.Name, err)
-			continue
+			return nil, nil, err
 		}
+		bundles = append(bundles, lib)
+	default:
+		return nil, nil, fmt.Errorf("unknown engine %q (expected go or rego)", options.Engine)
+	}
+	return bundles, selected, nil
+}
 
-		// Use OPA to evaluate the pod against policies
-		// This is a placeholder - in a real implementation, you would load policies from a directory
-		policyPath := "internal/policies/privileged_pod.rego"
-		if _, err := os.Stat(policyPath); err == nil {
-			reasons, err := opa.Evaluate(podYAML, policyPath)
-			if err != nil {
-				fmt.Printf("Warning: Failed to evaluate pod %s/%s with OPA: %v\n", pod.Namespace, pod.Name, err)
-				continue
-			}
+// scan evaluates the objects in the inventory against the selected built-in
+// checks and the policies, and applies the suppressions. scanErrors are the
+// errors of collecting the inventory.
+func scan(inventory *opa.Inventory, cluster string, bundles []*bundle.Bundle, selected []checks.Check, options Options, scanErrors []ScanError) ([]auditor.AuditFinding, error) {
+	var findings []auditor.AuditFinding
 
-			for _, reason := range reasons {
-				findings = append(findings, auditor.AuditFinding{
-					Resource:  "Pod",
-					Namespace: pod.Namespace,
-					Name:      pod.Name,
-					Reason:    reason,
-					Severity:  "High", // Default severity, could be determined by policy
-				})
+	// With the Rego engine the built-in checks are part of the bundles
+	if options.Engine != EngineRego {
+		findings = append(findings, checks.Run(selected, inventory.Objects())...)
+	}
+
+	// Evaluate every scanned object against the OPA policies, with the full
+	// inventory available as data.inventory for cross-resource rules
+	policyPaths := options.PolicyPaths
+	if len(options.PolicyPaths) == 0 && len(options.PolicyBundles) == 0 {
+		if _, err := os.Stat(opa.DefaultPolicyDir); err == nil {
+			policyPaths = []string{opa.DefaultPolicyDir}
+		}
+	}
+	if len(policyPaths) > 0 || len(bundles) > 0 {
+		fmt.Println("Evaluating OPA policies...")
+		policyFindings, evalErrors, err := evaluatePolicies(policyPaths, opa.EvaluatorOptions{
+			Inventory: inventory,
+			Bundles:   bundles,
+			Params:    options.Params,
+			Wasm:      options.Wasm,
+		})
+		if err != nil {
+			return nil, err
+		}
+		scanErrors = append(scanErrors, evalErrors...)
+		findings = append(findings, selectedFindings(policyFindings, selected)...)
+	}
+
+	// Evaluate every scanned object against the Kyverno validate rules found
+	// in the policy paths
+	policies, err := kyverno.Load(policyPaths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Kyverno policies: %w", err)
+	}
+	if len(policies) > 0 {
+		fmt.Println("Evaluating Kyverno policies...")
+		for _, obj := range inventory.Objects() {
+			for _, policy := range policies {
+				metadata, _ := obj["metadata"].(map[string]interface{})
+				namespace, _ := metadata["namespace"].(string)
+				policyFindings, err := policy.Evaluate(obj, inventory.NamespaceLabels(namespace))
+				if err != nil {
+					scanErrors = append(scanErrors, ScanError{
+						Resource: objectName(obj),
+						Err:      fmt.Errorf("failed to evaluate Kyverno policy %s: %w", policy.Name, err),
+					})
+					continue
+				}
+				findings = append(findings, policyFindings...)
 			}
 		}
 	}
 
+	index := newResourceIndex(inventory)
+	findings = options.Suppressions.Apply(findings, index.labels, time.Now())
+	if !options.DisableAnnotations {
+		findings = suppression.ApplyAnnotations(findings, index.annotations)
+	}
+
+	for i := range findings {
+		findings[i].Cluster = cluster
+	}
+	auditor.SetFingerprints(findings)
+	if len(scanErrors) > 0 {
+		return findings, &PartialError{Errors: scanErrors}
+	}
+	return findings, nil
+}
+
+// resourceKinds are the resource kinds collected from the cluster, in the
+// order they are listed
+var resourceKinds = []struct {
+	name    string
+	message string
+	list    func(kubernetes.Interface, *Resources) error
+}{
+	{"pods", "Scanning pods...", func(c kubernetes.Interface, r *Resources) error {
+		list, err := c.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+		if err == nil {
+			r.Pods = list.Items
+		}
+		return err
+	}},
 	// Scan services for potential security issues
-	fmt.Println("Scanning services...")
-	services, err := clientset.CoreV1().Services("").List(context.TODO(), metav1.ListOptions{})
+	{"services", "Scanning services...", func(c kubernetes.Interface, r *Resources) error {
+		list, err := c.CoreV1().Services("").List(context.TODO(), metav1.ListOptions{})
+		if err == nil {
+			r.Services = list.Items
+		}
+		return err
+	}},
+	// Scan RBAC roles for excessive permissions
+	{"roles", "Scanning RBAC roles...", func(c kubernetes.Interface, r *Resources) error {
+		list, err := c.RbacV1().Roles("").List(context.TODO(), metav1.ListOptions{})
+		if err == nil {
+			r.Roles = list.Items
+		}
+		return err
+	}},
+	// Scan namespaces for PodSecurity settings
+	{"namespaces", "Scanning namespaces...", func(c kubernetes.Interface, r *Resources) error {
+		list, err := c.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+		if err == nil {
+			r.Namespaces = list.Items
+		}
+		return err
+	}},
+	// Network policies carry no findings of their own but are collected so
+	// that policies can check which workloads they cover
+	{"network policies", "Scanning network policies...", func(c kubernetes.Interface, r *Resources) error {
+		list, err := c.NetworkingV1().NetworkPolicies("").List(context.TODO(), metav1.ListOptions{})
+		if err == nil {
+			r.NetworkPolicies = list.Items
+		}
+		return err
+	}},
+}
+
+// collectResources lists the resources the audit checks. A kind that cannot
+// be listed, for example because RBAC forbids it, is reported as a ScanError
+// and the other kinds are still collected.
+func collectResources(clientset kubernetes.Interface) (Resources, []ScanError) {
+	var resources Resources
+	var scanErrors []ScanError
+	for _, kind := range resourceKinds {
+		fmt.Println(kind.message)
+		if err := kind.list(clientset, &resources); err != nil {
+			scanErrors = append(scanErrors, ScanError{Resource: kind.name, Err: fmt.Errorf("failed to list %s: %w", kind.name, err)})
+		}
+	}
+	return resources, scanErrors
+}
+
+// selectedFindings drops the findings of built-in checks that are not
+// selected, so that the Rego ports of the checks honour the selection too.
+// Findings of other rules are kept.
+func selectedFindings(findings []auditor.AuditFinding, selected []checks.Check) []auditor.AuditFinding {
+	enabled := make(map[string]bool, len(selected))
+	for _, c := range selected {
+		enabled[c.ID()] = true
+	}
+
+	var kept []auditor.AuditFinding
+	for _, f := range findings {
+		if _, builtin := checks.Default().Get(f.RuleID); builtin && !enabled[f.RuleID] {
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return kept
+}
+
+// newInventory records every collected resource in an inventory
+func newInventory(resources Resources) *opa.Inventory {
+	inventory := opa.NewInventory()
+	for i := range resources.Pods {
+		addToInventory(inventory, &resources.Pods[i], "v1", "Pod")
+	}
+	for i := range resources.Services {
+		addToInventory(inventory, &resources.Services[i], "v1", "Service")
+	}
+	for i := range resources.Roles {
+		addToInventory(inventory, &resources.Roles[i], "rbac.authorization.k8s.io/v1", "Role")
+	}
+	for i := range resources.Namespaces {
+		addToInventory(inventory, &resources.Namespaces[i], "v1", "Namespace")
+	}
+	for i := range resources.NetworkPolicies {
+		addToInventory(inventory, &resources.NetworkPolicies[i], "networking.k8s.io/v1", "NetworkPolicy")
+	}
+	return inventory
+}
+
+// evaluatePolicies evaluates every object in the inventory of options against
+// the Rego policies and Gatekeeper Constraints at policyPaths and in the
+// bundles of options. Objects that fail to evaluate are returned as
+// ScanErrors.
+func evaluatePolicies(policyPaths []string, options opa.EvaluatorOptions) ([]auditor.AuditFinding, []ScanError, error) {
+	evaluator, err := opa.NewEvaluatorWithOptions(policyPaths, options)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list services: %v", err)
+		return nil, nil, fmt.Errorf("failed to load OPA policies: %w", err)
+	}
+	for _, id := range options.Params.UnknownRules(evaluator.Rules()) {
+		fmt.Printf("Warning: Parameters are set for %s, which is not a policy rule\n", id)
 	}
 
-	// Check for NodePort and LoadBalancer services
-	for _, svc := range services.Items {
-		if svc.Spec.Type == corev1.ServiceTypeNodePort {
-			findings = append(findings, auditor.AuditFinding{
-				Resource:  "Service",
-				Namespace: svc.Namespace,
-				Name:      svc.Name,
-				Reason:    "Service uses NodePort which exposes ports on all nodes",
-				Severity:  "Medium",
-			})
+	inventory := options.Inventory
+
+	var findings []auditor.AuditFinding
+	var scanErrors []ScanError
+	for _, obj := range inventory.Objects() {
+		kind, _ := obj["kind"].(string)
+		metadata, _ := obj["metadata"].(map[string]interface{})
+		namespace, _ := metadata["namespace"].(string)
+		name, _ := metadata["name"].(string)
+		if kind == "Namespace" {
+			// Findings on a Namespace are reported under the namespace itself
+			namespace = name
+		}
+
+		violations, err := evaluator.Evaluate(obj)
+		if err != nil {
+			scanErrors = append(scanErrors, ScanError{Resource: objectName(obj), Err: fmt.Errorf("failed to evaluate with OPA: %w", err)})
+			continue
 		}
-		if svc.Spec.Type == corev1.ServiceTypeLoadBalancer {
+
+		for _, violation := range violations {
+			severity := violation.Severity
+			if severity == "" {
+				severity = auditor.SeverityHigh // Default severity for policies that don't set one
+			}
 			findings = append(findings, auditor.AuditFinding{
-				Resource:  "Service",
-				Namespace: svc.Namespace,
-				Name:      svc.Name,
-				Reason:    "Service uses LoadBalancer which may expose the service to the internet",
-				Severity:  "Medium",
+				Resource:  kind,
+				Namespace: namespace,
+				Name:      name,
+				Reason:    violation.Message,
+				Severity:  severity,
+				RuleID:    violation.Policy,
+				Title:     violation.Title,
+				Container: violation.Container,
 			})
 		}
 	}
+	return findings, scanErrors, nil
+}
 
-	// Scan RBAC roles for excessive permissions
-	fmt.Println("Scanning RBAC roles...")
-	roles, err := clientset.RbacV1().Roles("").List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to list roles: %v", err)
+// resourceIndex holds the labels and annotations of the scanned resources,
+// keyed by the kind, namespace and name findings report them under
+type resourceIndex map[string]resourceMetadata
+
+// resourceMetadata is the labels and annotations of a resource
+type resourceMetadata struct {
+	labels      map[string]string
+	annotations map[string]string
+}
+
+// newResourceIndex indexes the resources in the inventory
+func newResourceIndex(inventory *opa.Inventory) resourceIndex {
+	index := make(resourceIndex)
+	for _, obj := range inventory.Objects() {
+		metadata, _ := obj["metadata"].(map[string]interface{})
+		index[objectKey(obj)] = resourceMetadata{
+			labels:      stringMap(metadata["labels"]),
+			annotations: stringMap(metadata["annotations"]),
+		}
 	}
+	return index
+}
 
-	for _, role := range roles.Items {
-		for _, rule := range role.Rules {
-			// Check for wildcard resources or verbs
-			hasWildcardResources := false
-			hasWildcardVerbs := false
+// labels returns the labels of the resource of a finding
+func (index resourceIndex) labels(f auditor.AuditFinding) map[string]string {
+	return index[resourceKey(f.Resource, f.Namespace, f.Name)].labels
+}
 
-			for _, resource := range rule.Resources {
-				if resource == "*" {
-					hasWildcardResources = true
-					break
-				}
-			}
+// annotations returns the annotations of the resource of a finding and of its
+// namespace
+func (index resourceIndex) annotations(f auditor.AuditFinding) (resource, namespace map[string]string) {
+	return index[resourceKey(f.Resource, f.Namespace, f.Name)].annotations,
+		index[resourceKey("Namespace", f.Namespace, f.Namespace)].annotations
+}
 
-			for _, verb := range rule.Verbs {
-				if verb == "*" {
-					hasWildcardVerbs = true
-					break
-				}
-			}
+// resourceKey returns the key of a resource in a resourceIndex
+func resourceKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
 
-			if hasWildcardResources && hasWildcardVerbs {
-				findings = append(findings, auditor.AuditFinding{
-					Resource:  "Role",
-					Namespace: role.Namespace,
-					Name:      role.Name,
-					Reason:    "Role has wildcard resources and verbs which grants excessive permissions",
-					Severity:  "High",
-				})
-				break
-			}
-		}
+// objectKey returns the key of an unstructured object in a resourceIndex
+func objectKey(obj map[string]interface{}) string {
+	kind, _ := obj["kind"].(string)
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	namespace, _ := metadata["namespace"].(string)
+	name, _ := metadata["name"].(string)
+	if kind == "Namespace" {
+		// Findings on a Namespace are reported under the namespace itself
+		namespace = name
 	}
+	return resourceKey(kind, namespace, name)
+}
 
-	// Scan namespaces for PodSecurity settings
-	fmt.Println("Scanning namespaces...")
-	namespaces, err := clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+// stringMap converts the labels or annotations of an unstructured object
+func stringMap(value interface{}) map[string]string {
+	values, _ := value.(map[string]interface{})
+	if values == nil {
+		return nil
+	}
+	result := make(map[string]string, len(values))
+	for key, value := range values {
+		result[key], _ = value.(string)
+	}
+	return result
+}
+
+// objectName returns the kind, namespace and name of an unstructured object,
+// such as "Pod default/web"
+func objectName(obj map[string]interface{}) string {
+	kind, _ := obj["kind"].(string)
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	namespace, _ := metadata["namespace"].(string)
+	name, _ := metadata["name"].(string)
+	if namespace == "" {
+		return kind + " " + name
+	}
+	return kind + " " + namespace + "/" + name
+}
+
+// addToInventory converts a typed object to its unstructured form and records
+// it in the inventory. Objects returned by List calls have an empty TypeMeta,
+// so apiVersion and kind are set explicitly.
+func addToInventory(inventory *opa.Inventory, obj runtime.Object, apiVersion, kind string) {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list namespaces: %v", err)
+		fmt.Printf("Warning: Failed to convert %s to unstructured: %v\n", kind, err)
+		return
 	}
+	u["apiVersion"] = apiVersion
+	u["kind"] = kind
+	inventory.Add(u)
+}
 
-	for _, ns := range namespaces.Items {
-		enforce := ns.Labels["pod-security.kubernetes.io/enforce"]
-		if enforce == "" || enforce == "privileged" {
-			findings = append(findings, auditor.AuditFinding{
-				Resource:  "Namespace",
-				Namespace: ns.Name,
-				Name:      ns.Name,
-				Reason:    "Namespace does not enforce PodSecurity standards or uses 'privileged' level",
-				Severity:  "High",
-			})
-		}
+// currentCluster returns the name of the cluster of the current kubeconfig
+// context, or an empty string if it cannot be determined
+func currentCluster(kubeConfigPath string) string {
+	config, err := clientcmd.LoadFromFile(kubeConfigPath)
+	if err != nil {
+		return ""
+	}
+	if kubeContext, ok := config.Contexts[config.CurrentContext]; ok {
+		return kubeContext.Cluster
 	}
+	return ""
+}
 
-	return findings, nil
+// kubeConfigPath returns the path of the kubeconfig file
+func kubeConfigPath() string {
+	return filepath.Join(homedir.HomeDir(), ".kube", "config")
+}
+
+// RESTConfig returns the client configuration of the current kubeconfig
+// context, the cluster ScanCluster scans
+func RESTConfig() (*rest.Config, error) {
+	return loadKubeConfig(kubeConfigPath())
 }
 
 func loadKubeConfig(kubeConfigPath string) (*rest.Config, error) {
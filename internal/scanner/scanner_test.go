@@ -0,0 +1,266 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/open-policy-agent/opa/bundle"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/library"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/manifest"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/opa"
+	"github.com/vibhordubey333/k8s-devguardian-ai/pkg/checks"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// loadResources reads the parity fixtures into typed resources, as they would
+// be returned by the cluster
+func loadResources(t *testing.T) Resources {
+	t.Helper()
+	objects, err := manifest.Load([]string{filepath.Join("testdata", "parity")})
+	if err != nil {
+		t.Fatalf("Failed to load fixtures: %v", err)
+	}
+
+	var resources Resources
+	for _, obj := range objects {
+		var target interface{}
+		switch obj.Kind() {
+		case "Pod":
+			resources.Pods = append(resources.Pods, corev1.Pod{})
+			target = &resources.Pods[len(resources.Pods)-1]
+		case "Service":
+			resources.Services = append(resources.Services, corev1.Service{})
+			target = &resources.Services[len(resources.Services)-1]
+		case "Role":
+			resources.Roles = append(resources.Roles, rbacv1.Role{})
+			target = &resources.Roles[len(resources.Roles)-1]
+		case "Namespace":
+			resources.Namespaces = append(resources.Namespaces, corev1.Namespace{})
+			target = &resources.Namespaces[len(resources.Namespaces)-1]
+		case "NetworkPolicy":
+			resources.NetworkPolicies = append(resources.NetworkPolicies, networkingv1.NetworkPolicy{})
+			target = &resources.NetworkPolicies[len(resources.NetworkPolicies)-1]
+		default:
+			t.Fatalf("Unexpected fixture kind %s", obj.Kind())
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, target); err != nil {
+			t.Fatalf("Failed to convert %s/%s: %v", obj.Kind(), obj.Name(), err)
+		}
+	}
+	return resources
+}
+
+// findingKeys returns the findings sorted for comparison
+func findingKeys(findings []auditor.AuditFinding) []auditor.AuditFinding {
+	keys := append([]auditor.AuditFinding{}, findings...)
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Reason < b.Reason
+	})
+	return keys
+}
+
+func TestEngines_Parity(t *testing.T) {
+	resources := loadResources(t)
+
+	goFindings := findingKeys(checks.Run(checks.Default().Checks(), newInventory(resources).Objects()))
+
+	lib, err := library.Bundle()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	regoFindings, scanErrors, err := evaluatePolicies(nil, opa.EvaluatorOptions{Inventory: newInventory(resources), Bundles: []*bundle.Bundle{lib}})
+	if err != nil || len(scanErrors) > 0 {
+		t.Fatalf("Expected no error, got %v %v", err, scanErrors)
+	}
+	// Guard against the fixtures silently producing nothing
+	if len(goFindings) != 14 {
+		t.Errorf("Expected 14 findings from the Go engine, got %d", len(goFindings))
+	}
+
+	regoKeys := findingKeys(regoFindings)
+	if len(goFindings) != len(regoKeys) {
+		t.Errorf("Go engine produced %d findings, Rego engine %d", len(goFindings), len(regoKeys))
+	}
+	for i := 0; i < len(goFindings) || i < len(regoKeys); i++ {
+		var g, r auditor.AuditFinding
+		if i < len(goFindings) {
+			g = goFindings[i]
+		}
+		if i < len(regoKeys) {
+			r = regoKeys[i]
+		}
+		if g != r {
+			t.Errorf("Finding %d differs:\n  go:   %+v\n  rego: %+v", i, g, r)
+		}
+	}
+}
+
+func TestSelectedFindings(t *testing.T) {
+	selected, err := checks.Default().Select(nil, []string{"DG-SVC-*"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	findings := selectedFindings([]auditor.AuditFinding{
+		{Name: "pod", RuleID: "DG-POD-001"},
+		{Name: "svc", RuleID: "DG-SVC-001"},
+		{Name: "custom", RuleID: "ACME-001"},
+		{Name: "unannotated"},
+	}, selected)
+
+	var names []string
+	for _, f := range findings {
+		names = append(names, f.Name)
+	}
+	if len(names) != 3 || names[0] != "pod" || names[1] != "custom" || names[2] != "unannotated" {
+		t.Errorf("Expected the disabled check's finding to be dropped, got %v", names)
+	}
+}
+
+func TestCollectResources_Forbidden(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"}})
+	clientset.PrependReactor("list", "roles", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "roles"}, "", nil)
+	})
+
+	resources, scanErrors := collectResources(clientset)
+	if len(resources.Pods) != 1 {
+		t.Errorf("Expected the pods to be collected, got %d", len(resources.Pods))
+	}
+	if len(scanErrors) != 1 || scanErrors[0].Resource != "roles" || !apierrors.IsForbidden(scanErrors[0].Err) {
+		t.Fatalf("Expected a forbidden error for roles, got %v", scanErrors)
+	}
+
+	err := &PartialError{Errors: scanErrors}
+	if !strings.Contains(err.Error(), "scan incomplete: roles: failed to list roles") {
+		t.Errorf("Unexpected message %q", err.Error())
+	}
+}
+
+func TestResourceIndex(t *testing.T) {
+	index := newResourceIndex(newInventory(Resources{
+		Pods: []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "kube-system",
+			Name:        "calico-node-x2b7k",
+			Labels:      map[string]string{"k8s-app": "calico-node"},
+			Annotations: map[string]string{"devguardian.io/ignore": "DG-POD-002"},
+		}}},
+		Namespaces: []corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{
+			Name:        "kube-system",
+			Labels:      map[string]string{"team": "platform"},
+			Annotations: map[string]string{"devguardian.io/ignore": "DG-POD-001"},
+		}}},
+	}))
+
+	pod := auditor.AuditFinding{Resource: "Pod", Namespace: "kube-system", Name: "calico-node-x2b7k"}
+	if got := index.labels(pod); got["k8s-app"] != "calico-node" {
+		t.Errorf("Expected the pod's labels, got %v", got)
+	}
+	resource, namespace := index.annotations(pod)
+	if resource["devguardian.io/ignore"] != "DG-POD-002" || namespace["devguardian.io/ignore"] != "DG-POD-001" {
+		t.Errorf("Expected the pod's and namespace's annotations, got %v and %v", resource, namespace)
+	}
+	// Findings on a Namespace are reported under the namespace itself
+	if got := index.labels(auditor.AuditFinding{Resource: "Namespace", Namespace: "kube-system", Name: "kube-system"}); got["team"] != "platform" {
+		t.Errorf("Expected the namespace's labels, got %v", got)
+	}
+	if got := index.labels(auditor.AuditFinding{Resource: "Pod", Namespace: "default", Name: "web"}); got != nil {
+		t.Errorf("Expected no labels for an unknown resource, got %v", got)
+	}
+}
+
+func TestScanManifests(t *testing.T) {
+	findings, err := ScanManifests([]string{filepath.Join("testdata", "parity")}, Options{Cluster: "ci"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(findings) != 14 {
+		t.Fatalf("Expected 14 findings, got %d", len(findings))
+	}
+	for _, f := range findings {
+		if f.Cluster != "ci" || f.Fingerprint == "" {
+			t.Errorf("Expected the cluster and fingerprint to be set, got %+v", f)
+		}
+		if f.Resource == "Pod" && f.Name == "node-agent" && (f.File != filepath.Join("testdata", "parity", "pods.yaml") || f.Line != 2) {
+			t.Errorf("Expected node-agent findings at pods.yaml:2, got %s:%d", f.File, f.Line)
+		}
+		if f.File == "" || f.Line == 0 {
+			t.Errorf("Expected a location for %s/%s/%s", f.Resource, f.Namespace, f.Name)
+		}
+	}
+}
+
+func TestScanManifests_KyvernoNamespaceFinding(t *testing.T) {
+	dir := t.TempDir()
+	policyDir := filepath.Join(dir, "policies")
+	os.Mkdir(policyDir, 0755)
+	os.WriteFile(filepath.Join(policyDir, "require-owner.yaml"), []byte(`apiVersion: kyverno.io/v1
+kind: ClusterPolicy
+metadata:
+  name: require-owner
+spec:
+  rules:
+    - name: check-owner
+      match:
+        any:
+          - resources:
+              kinds: ["Namespace"]
+      validate:
+        message: "An owner label is required"
+        pattern:
+          metadata:
+            labels:
+              owner: "?*"
+`), 0644)
+	manifests := filepath.Join(dir, "namespaces.yaml")
+	os.WriteFile(manifests, []byte(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: legacy
+  annotations:
+    devguardian.io/ignore: require-owner/check-owner
+    devguardian.io/ignore-reason: Owned by the platform team
+`), 0644)
+
+	findings, err := ScanManifests([]string{manifests}, Options{PolicyPaths: []string{policyDir}, Cluster: "ci"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var found []auditor.AuditFinding
+	for _, f := range findings {
+		if f.RuleID == "require-owner/check-owner" {
+			found = append(found, f)
+		}
+	}
+	if len(found) != 1 {
+		t.Fatalf("Expected 1 Kyverno finding, got %+v", found)
+	}
+	f := found[0]
+	if f.Namespace != "legacy" || f.File != manifests || f.Line != 1 {
+		t.Errorf("Expected the finding under namespace legacy at %s:1, got %q at %s:%d", manifests, f.Namespace, f.File, f.Line)
+	}
+	if !f.Suppressed || f.Justification != "Owned by the platform team" {
+		t.Errorf("Expected the finding to be suppressed by the annotation, got %+v", f)
+	}
+}
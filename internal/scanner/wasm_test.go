@@ -0,0 +1,137 @@
+//go:build opa_wasm
+
+package scanner
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/open-policy-agent/opa/bundle"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/library"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/opa"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWasm_Parity(t *testing.T) {
+	resources := loadResources(t)
+	lib, err := library.Bundle()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	regoFindings, scanErrors, err := evaluatePolicies(nil, opa.EvaluatorOptions{Inventory: newInventory(resources), Bundles: []*bundle.Bundle{lib}})
+	if err != nil || len(scanErrors) > 0 {
+		t.Fatalf("Expected no error, got %v %v", err, scanErrors)
+	}
+	wasmFindings, scanErrors, err := evaluatePolicies(nil, opa.EvaluatorOptions{Inventory: newInventory(resources), Bundles: []*bundle.Bundle{lib}, Wasm: true})
+	if err != nil || len(scanErrors) > 0 {
+		t.Fatalf("Expected no error, got %v %v", err, scanErrors)
+	}
+	if len(regoFindings) == 0 {
+		t.Fatal("Expected findings from the Rego evaluator")
+	}
+	if got, want := findingKeys(wasmFindings), findingKeys(regoFindings); !reflect.DeepEqual(got, want) {
+		t.Errorf("Wasm findings differ from Rego findings:\n  wasm: %+v\n  rego: %+v", got, want)
+	}
+}
+
+// podCorpus returns n pods spread over 20 namespaces, a quarter of which run
+// as root, are privileged or mount a hostPath volume
+func podCorpus(n int) Resources {
+	var resources Resources
+	for i := 0; i < 20; i++ {
+		resources.Namespaces = append(resources.Namespaces, corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("team-%d", i)},
+		})
+	}
+
+	root, privileged := int64(0), true
+	for i := 0; i < n; i++ {
+		pod := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i), Namespace: fmt.Sprintf("team-%d", i%20)},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "app", Image: "registry.example.com/app:1.0"},
+					{Name: "sidecar", Image: "registry.example.com/proxy:1.0"},
+				},
+			},
+		}
+		switch i % 8 {
+		case 0:
+			pod.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{RunAsUser: &root}
+		case 1:
+			pod.Spec.Containers[1].SecurityContext = &corev1.SecurityContext{Privileged: &privileged}
+		case 2:
+			pod.Spec.Volumes = []corev1.Volume{{
+				Name:         "host",
+				VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/run"}},
+			}}
+		}
+		resources.Pods = append(resources.Pods, pod)
+	}
+	return resources
+}
+
+// BenchmarkEvaluate compares the Rego evaluator with the Wasm runtime on the
+// default Rego library, evaluating every pod of the corpus per iteration.
+// Compiling the policies is measured separately by BenchmarkNewEvaluator. Run
+// with:
+//
+//	CGO_ENABLED=1 go test -tags opa_wasm -run '^$' -bench . ./internal/scanner
+func BenchmarkEvaluate(b *testing.B) {
+	lib, err := library.Bundle()
+	if err != nil {
+		b.Fatalf("Expected no error, got %v", err)
+	}
+	inventory := newInventory(podCorpus(5000))
+	objects := inventory.Objects()
+
+	for _, engine := range benchmarkEngines {
+		b.Run(engine.name, func(b *testing.B) {
+			evaluator, err := engine.new(nil, inventory, lib)
+			if err != nil {
+				b.Fatalf("Expected no error, got %v", err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, obj := range objects {
+					if _, err := evaluator.Evaluate(obj); err != nil {
+						b.Fatalf("Expected no error, got %v", err)
+					}
+				}
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(objects)), "ns/object")
+		})
+	}
+}
+
+// BenchmarkNewEvaluator measures compiling the default Rego library with an
+// inventory of the pod corpus
+func BenchmarkNewEvaluator(b *testing.B) {
+	lib, err := library.Bundle()
+	if err != nil {
+		b.Fatalf("Expected no error, got %v", err)
+	}
+	inventory := newInventory(podCorpus(5000))
+
+	for _, engine := range benchmarkEngines {
+		b.Run(engine.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := engine.new(nil, inventory, lib); err != nil {
+					b.Fatalf("Expected no error, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+// benchmarkEngines are the evaluators compared by the benchmarks
+var benchmarkEngines = []struct {
+	name string
+	new  func([]string, *opa.Inventory, ...*bundle.Bundle) (*opa.Evaluator, error)
+}{
+	{"rego", opa.NewEvaluator},
+	{"wasm", opa.NewWasmEvaluator},
+}
@@ -0,0 +1,63 @@
+package suppression
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/wildcard"
+)
+
+const (
+	// IgnoreAnnotation lists the rule IDs whose findings on a resource are
+	// suppressed, separated by commas. Rule IDs may contain wildcards, where
+	// "*" also matches the "/" of Kyverno rule IDs. On a Namespace it applies
+	// to every resource in the namespace.
+	IgnoreAnnotation = "devguardian.io/ignore"
+	// IgnoreReasonAnnotation explains why the rules in IgnoreAnnotation are
+	// suppressed
+	IgnoreReasonAnnotation = "devguardian.io/ignore-reason"
+)
+
+// ApplyAnnotations marks the findings whose rule is listed in the
+// IgnoreAnnotation of their resource, or of the resource's namespace, as
+// suppressed. The justification is the IgnoreReasonAnnotation next to it.
+// annotations returns the annotations of the resource of a finding and of its
+// namespace.
+func ApplyAnnotations(findings []auditor.AuditFinding, annotations func(auditor.AuditFinding) (resource, namespace map[string]string)) []auditor.AuditFinding {
+	for i := range findings {
+		f := &findings[i]
+		if f.Suppressed || f.RuleID == "" {
+			continue
+		}
+		resource, namespace := annotations(*f)
+		if reason, ok := ignored(resource, f.RuleID); ok {
+			f.Suppressed = true
+			f.Justification = justification(reason, fmt.Sprintf("%s annotation on %s %s", IgnoreAnnotation, f.Resource, f.Name))
+		} else if reason, ok := ignored(namespace, f.RuleID); ok {
+			f.Suppressed = true
+			f.Justification = justification(reason, fmt.Sprintf("%s annotation on namespace %s", IgnoreAnnotation, f.Namespace))
+		}
+	}
+	return findings
+}
+
+// ignored reports whether the IgnoreAnnotation in annotations lists the rule,
+// and returns the IgnoreReasonAnnotation
+func ignored(annotations map[string]string, ruleID string) (string, bool) {
+	for _, pattern := range strings.Split(annotations[IgnoreAnnotation], ",") {
+		if wildcard.Match(strings.TrimSpace(pattern), ruleID) {
+			return annotations[IgnoreReasonAnnotation], true
+		}
+	}
+	return "", false
+}
+
+// justification returns the reason given with an annotation, or a mention of
+// the annotation if none was given
+func justification(reason, source string) string {
+	if reason == "" {
+		return "Suppressed by the " + source
+	}
+	return reason
+}
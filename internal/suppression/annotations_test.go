@@ -0,0 +1,74 @@
+package suppression
+
+import (
+	"testing"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+)
+
+func TestApplyAnnotations(t *testing.T) {
+	annotations := map[string]map[string]string{
+		"web": {
+			IgnoreAnnotation:       "DG-POD-001, DG-POD-003",
+			IgnoreReasonAnnotation: "Legacy image, tracked in OPS-42",
+		},
+		"cni": {IgnoreAnnotation: "DG-POD-*"},
+		"legacy": {
+			IgnoreAnnotation:       "DG-SVC-002",
+			IgnoreReasonAnnotation: "Public by design",
+		},
+	}
+	lookup := func(f auditor.AuditFinding) (map[string]string, map[string]string) {
+		return annotations[f.Name], annotations[f.Namespace]
+	}
+
+	findings := ApplyAnnotations([]auditor.AuditFinding{
+		{Resource: "Pod", Namespace: "default", Name: "web", RuleID: "DG-POD-001"},
+		{Resource: "Pod", Namespace: "default", Name: "web", RuleID: "DG-POD-002"},
+		{Resource: "Pod", Namespace: "default", Name: "web", RuleID: "DG-POD-003"},
+		{Resource: "Pod", Namespace: "kube-system", Name: "cni", RuleID: "DG-POD-002"},
+		{Resource: "Service", Namespace: "legacy", Name: "api", RuleID: "DG-SVC-002"},
+		{Resource: "Service", Namespace: "legacy", Name: "api", RuleID: "DG-SVC-001"},
+		{Resource: "Pod", Namespace: "default", Name: "web", Reason: "no rule ID"},
+	}, lookup)
+
+	expected := []struct {
+		suppressed    bool
+		justification string
+	}{
+		{true, "Legacy image, tracked in OPS-42"},
+		{false, ""},
+		{true, "Legacy image, tracked in OPS-42"},
+		{true, "Suppressed by the devguardian.io/ignore annotation on Pod cni"},
+		{true, "Public by design"},
+		{false, ""},
+		{false, ""},
+	}
+	for i, want := range expected {
+		if findings[i].Suppressed != want.suppressed || findings[i].Justification != want.justification {
+			t.Errorf("Finding %d: expected suppressed=%v %q, got %+v", i, want.suppressed, want.justification, findings[i])
+		}
+	}
+
+	// Findings already suppressed keep their justification
+	findings = ApplyAnnotations([]auditor.AuditFinding{
+		{Resource: "Pod", Namespace: "default", Name: "web", RuleID: "DG-POD-001", Suppressed: true, Justification: "From the file"},
+	}, lookup)
+	if findings[0].Justification != "From the file" {
+		t.Errorf("Expected the existing justification to be kept, got %q", findings[0].Justification)
+	}
+}
+
+func TestApplyAnnotations_KyvernoRuleID(t *testing.T) {
+	for _, pattern := range []string{"*", "require-*", "DG-POD-001, require-owner/*"} {
+		lookup := func(auditor.AuditFinding) (map[string]string, map[string]string) {
+			return map[string]string{IgnoreAnnotation: pattern}, nil
+		}
+		findings := ApplyAnnotations([]auditor.AuditFinding{
+			{Resource: "Namespace", Namespace: "legacy", Name: "legacy", RuleID: "require-owner/check-owner"},
+		}, lookup)
+		if !findings[0].Suppressed {
+			t.Errorf("Expected %q to ignore the Kyverno finding, got %+v", pattern, findings[0])
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Package suppression marks findings accepted as risks as suppressed, as
+// recorded in the suppression file or in annotations on the resources
+package suppression
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/wildcard"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DefaultFile is the suppression file read from the working directory
+	// when no file is given
+	DefaultFile = ".devguardianignore"
+	// dateLayout is the layout of expiry dates
+	dateLayout = "2006-01-02"
+	// ExpiredRuleID is the rule ID of the findings reporting expired
+	// suppressions
+	ExpiredRuleID = "DG-SUPP-001"
+)
+
+// File is a suppression file:
+//
+//	suppressions:
+//	- rule: DG-POD-002           # rule ID, wildcards allowed (* also matches "/")
+//	  namespace: kube-system
+//	  kind: Pod
+//	  name: calico-node-*        # wildcards allowed
+//	  selector:                  # label selector of the resource
+//	    matchLabels:
+//	      k8s-app: calico-node
+//	  justification: The CNI must be privileged to configure the node network
+//	  expires: 2026-12-31        # optional, suppresses up to and including this day
+type File struct {
+	Suppressions []Suppression `json:"suppressions"`
+
+	path string
+}
+
+// Suppression accepts the findings it matches as a risk. A finding is matched
+// when it matches every field that is set.
+type Suppression struct {
+	// Rule is the rule ID of the findings, and may contain wildcards. A "*"
+	// also matches the "/" of Kyverno rule IDs such as "policy/rule".
+	Rule string `json:"rule,omitempty"`
+	// Namespace is the namespace of the resource
+	Namespace string `json:"namespace,omitempty"`
+	// Kind is the kind of the resource, such as Pod
+	Kind string `json:"kind,omitempty"`
+	// Name is the name of the resource, and may contain wildcards
+	Name string `json:"name,omitempty"`
+	// Selector matches the labels of the resource
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Justification explains why the risk is accepted. It is required.
+	Justification string `json:"justification"`
+	// Expires is the last day the suppression applies, as YYYY-MM-DD. Expired
+	// suppressions no longer suppress anything and are reported as findings.
+	Expires string `json:"expires,omitempty"`
+
+	selector labels.Selector
+	expires  time.Time
+}
+
+// Load reads the suppression file at filePath. If it is empty DefaultFile is
+// read if it exists, and nil is returned otherwise. Unknown fields, invalid
+// patterns, selectors and dates, and suppressions without a justification are
+// rejected.
+func Load(filePath string) (*File, error) {
+	if filePath == "" {
+		if _, err := os.Stat(DefaultFile); err != nil {
+			return nil, nil
+		}
+		filePath = DefaultFile
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read suppressions: %w", err)
+	}
+	var file File
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse suppressions %s: %w", filePath, err)
+	}
+	file.path = filePath
+	for i := range file.Suppressions {
+		if err := file.Suppressions[i].compile(); err != nil {
+			return nil, fmt.Errorf("%s: suppression #%d: %w", filePath, i+1, err)
+		}
+	}
+	return &file, nil
+}
+
+// compile validates the suppression and prepares its selector and expiry date
+func (s *Suppression) compile() error {
+	if s.Justification == "" {
+		return fmt.Errorf("justification is required")
+	}
+	if s.Rule == "" && s.Namespace == "" && s.Kind == "" && s.Name == "" && s.Selector == nil {
+		return fmt.Errorf("at least one of rule, namespace, kind, name or selector is required")
+	}
+	if _, err := path.Match(s.Name, ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", s.Name, err)
+	}
+	if s.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(s.Selector)
+		if err != nil {
+			return fmt.Errorf("invalid selector: %w", err)
+		}
+		s.selector = selector
+	}
+	if s.Expires != "" {
+		expires, err := time.Parse(dateLayout, s.Expires)
+		if err != nil {
+			return fmt.Errorf("invalid expiry date %q (expected YYYY-MM-DD)", s.Expires)
+		}
+		// The suppression applies up to and including its expiry date
+		s.expires = expires.AddDate(0, 0, 1)
+	}
+	return nil
+}
+
+// Expired reports whether the suppression has expired at now
+func (s *Suppression) Expired(now time.Time) bool {
+	return !s.expires.IsZero() && !now.Before(s.expires)
+}
+
+// Matches reports whether the suppression matches a finding. resourceLabels
+// are the labels of the finding's resource.
+func (s *Suppression) Matches(f auditor.AuditFinding, resourceLabels map[string]string) bool {
+	if s.Rule != "" && !wildcard.Match(s.Rule, f.RuleID) {
+		return false
+	}
+	if s.Namespace != "" && s.Namespace != f.Namespace {
+		return false
+	}
+	if s.Kind != "" && s.Kind != f.Resource {
+		return false
+	}
+	if s.Name != "" {
+		if ok, _ := path.Match(s.Name, f.Name); !ok {
+			return false
+		}
+	}
+	if s.selector != nil && !s.selector.Matches(labels.Set(resourceLabels)) {
+		return false
+	}
+	return true
+}
+
+// Apply marks the findings matched by an unexpired suppression as suppressed,
+// with the suppression's justification, and returns them followed by a
+// finding for every expired suppression. resourceLabels returns the labels of
+// the resource of a finding. A nil File suppresses nothing.
+func (file *File) Apply(findings []auditor.AuditFinding, resourceLabels func(auditor.AuditFinding) map[string]string, now time.Time) []auditor.AuditFinding {
+	if file == nil {
+		return findings
+	}
+
+	var active []*Suppression
+	for i := range file.Suppressions {
+		s := &file.Suppressions[i]
+		if s.Expired(now) {
+			findings = append(findings, file.expiredFinding(i))
+			continue
+		}
+		active = append(active, s)
+	}
+
+	for i := range findings {
+		if findings[i].Suppressed || findings[i].RuleID == ExpiredRuleID {
+			continue
+		}
+		for _, s := range active {
+			if s.Matches(findings[i], resourceLabels(findings[i])) {
+				findings[i].Suppressed = true
+				findings[i].Justification = s.Justification
+				break
+			}
+		}
+	}
+	return findings
+}
+
+// expiredFinding returns the finding reporting that the i-th suppression has
+// expired
+func (file *File) expiredFinding(i int) auditor.AuditFinding {
+	s := file.Suppressions[i]
+	return auditor.AuditFinding{
+		Resource: "Suppression",
+		Name:     fmt.Sprintf("%s#%d", file.path, i+1),
+		Reason:   fmt.Sprintf("Suppression %s expired on %s, so the findings it matched are reported again (justification: %s)", s.describe(), s.Expires, s.Justification),
+		Severity: auditor.SeverityMedium,
+		RuleID:   ExpiredRuleID,
+		Title:    "Expired suppression",
+	}
+}
+
+// describe returns the fields the suppression matches on, such as
+// "rule=DG-POD-002 namespace=kube-system"
+func (s Suppression) describe() string {
+	var fields []string
+	for _, field := range []struct{ name, value string }{
+		{"rule", s.Rule}, {"namespace", s.Namespace}, {"kind", s.Kind}, {"name", s.Name},
+	} {
+		if field.value != "" {
+			fields = append(fields, field.name+"="+field.value)
+		}
+	}
+	if s.selector != nil {
+		fields = append(fields, "selector="+s.selector.String())
+	}
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,145 @@
+package suppression
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+)
+
+const suppressions = `suppressions:
+- rule: DG-POD-002
+  namespace: kube-system
+  name: calico-node-*
+  selector:
+    matchLabels:
+      k8s-app: calico-node
+  justification: The CNI must be privileged to configure the node network
+- rule: DG-SVC-*
+  kind: Service
+  justification: Exposed on purpose
+  expires: 2026-03-31
+`
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".devguardianignore")
+	os.WriteFile(path, []byte(suppressions), 0644)
+
+	file, err := Load(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(file.Suppressions) != 2 || file.Suppressions[1].Expires != "2026-03-31" {
+		t.Errorf("Unexpected suppressions: %+v", file.Suppressions)
+	}
+
+	for name, content := range map[string]string{
+		"no justification": "suppressions:\n- rule: DG-POD-002\n",
+		"no matcher":       "suppressions:\n- justification: Everything is fine\n",
+		"invalid date":     "suppressions:\n- rule: DG-POD-002\n  justification: x\n  expires: 31/03/2026\n",
+		"invalid pattern":  "suppressions:\n- name: '[web'\n  justification: x\n",
+		"unknown field":    "suppressions:\n- rules: DG-POD-002\n  justification: x\n",
+		"invalid selector": "suppressions:\n- selector:\n    matchLabels:\n      'bad key!': x\n  justification: x\n",
+	} {
+		os.WriteFile(path, []byte(content), 0644)
+		if _, err := Load(path); err == nil {
+			t.Errorf("Expected an error for %s", name)
+		}
+	}
+}
+
+func TestLoad_Default(t *testing.T) {
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	os.Chdir(t.TempDir())
+
+	file, err := Load("")
+	if err != nil || file != nil {
+		t.Fatalf("Expected no suppressions without a file, got %v, %v", file, err)
+	}
+	os.WriteFile(DefaultFile, []byte(suppressions), 0644)
+	if file, err = Load(""); err != nil || file == nil || len(file.Suppressions) != 2 {
+		t.Errorf("Expected the default file to be read, got %v, %v", file, err)
+	}
+}
+
+func TestFile_Apply(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "suppressions.yaml")
+	os.WriteFile(path, []byte(suppressions), 0644)
+	file, err := Load(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	findings := []auditor.AuditFinding{
+		{Resource: "Pod", Namespace: "kube-system", Name: "calico-node-x2b7k", RuleID: "DG-POD-002"},
+		{Resource: "Pod", Namespace: "kube-system", Name: "calico-node-unlabelled", RuleID: "DG-POD-002"},
+		{Resource: "Pod", Namespace: "default", Name: "calico-node-x2b7k", RuleID: "DG-POD-002"},
+		{Resource: "Pod", Namespace: "kube-system", Name: "calico-node-x2b7k", RuleID: "DG-POD-001"},
+		{Resource: "Service", Namespace: "default", Name: "web", RuleID: "DG-SVC-002"},
+	}
+	resourceLabels := func(f auditor.AuditFinding) map[string]string {
+		if f.Name == "calico-node-unlabelled" {
+			return nil
+		}
+		return map[string]string{"k8s-app": "calico-node"}
+	}
+
+	// The last day of a suppression is still covered
+	applied := file.Apply(append([]auditor.AuditFinding{}, findings...), resourceLabels, time.Date(2026, 3, 31, 23, 0, 0, 0, time.UTC))
+	expected := []bool{true, false, false, false, true}
+	if len(applied) != len(findings) {
+		t.Fatalf("Expected no expired suppressions, got %+v", applied[len(findings):])
+	}
+	for i, suppressed := range expected {
+		if applied[i].Suppressed != suppressed {
+			t.Errorf("Expected finding %d suppressed=%v, got %+v", i, suppressed, applied[i])
+		}
+	}
+	if applied[0].Justification != "The CNI must be privileged to configure the node network" {
+		t.Errorf("Expected the justification to be recorded, got %q", applied[0].Justification)
+	}
+
+	// Expired suppressions suppress nothing and are reported
+	applied = file.Apply(append([]auditor.AuditFinding{}, findings...), resourceLabels, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC))
+	if applied[4].Suppressed {
+		t.Error("Expected the expired suppression to no longer apply")
+	}
+	if len(applied) != len(findings)+1 {
+		t.Fatalf("Expected a finding for the expired suppression, got %+v", applied)
+	}
+	expired := applied[len(findings)]
+	if expired.RuleID != ExpiredRuleID || expired.Name != path+"#2" || expired.Severity != auditor.SeverityMedium ||
+		!strings.Contains(expired.Reason, "rule=DG-SVC-* kind=Service expired on 2026-03-31") {
+		t.Errorf("Unexpected expired suppression finding: %+v", expired)
+	}
+
+	var none *File
+	if got := none.Apply(findings, resourceLabels, time.Now()); len(got) != len(findings) || got[0].Suppressed {
+		t.Error("Expected a nil file to suppress nothing")
+	}
+}
+
+func TestSuppression_MatchesKyvernoRuleID(t *testing.T) {
+	finding := auditor.AuditFinding{Resource: "Namespace", Namespace: "legacy", Name: "legacy", RuleID: "require-owner/check-owner"}
+	for pattern, match := range map[string]bool{
+		"*":                         true,
+		"require-*":                 true,
+		"*/check-owner":             true,
+		"require-owner/check-owner": true,
+		"disallow-*/check-owner":    false,
+		"DG-*":                      false,
+	} {
+		s := Suppression{Rule: pattern, Justification: "x"}
+		if err := s.compile(); err != nil {
+			t.Fatalf("%q: expected no error, got %v", pattern, err)
+		}
+		if got := s.Matches(finding, nil); got != match {
+			t.Errorf("Rule %q matching %s = %v, expected %v", pattern, finding.RuleID, got, match)
+		}
+	}
+}
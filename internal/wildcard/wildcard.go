@@ -0,0 +1,30 @@
+// Package wildcard matches strings against the wildcard patterns of Kyverno
+// policies and DevGuardian suppressions. Unlike path.Match, "/" is an ordinary
+// character, so that "*" matches Kyverno rule IDs such as "policy/rule".
+package wildcard
+
+// Match reports whether s matches pattern, where * matches any sequence of
+// characters (including none) and ? matches a single character
+func Match(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := 0; i <= len(s); i++ {
+				if Match(pattern[1:], s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(s) == 0 {
+				return false
+			}
+		default:
+			if len(s) == 0 || s[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, s = pattern[1:], s[1:]
+	}
+	return len(s) == 0
+}
@@ -0,0 +1,25 @@
+package wildcard
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		match      bool
+	}{
+		{"*", "require-owner/check-owner", true},
+		{"require-*", "require-owner/check-owner", true},
+		{"*/check-owner", "require-owner/check-owner", true},
+		{"require-owner/*", "disallow-latest/check-tag", false},
+		{"DG-POD-00?", "DG-POD-002", true},
+		{"DG-POD-00?", "DG-POD-0021", false},
+		{"DG-POD-002", "DG-POD-002", true},
+		{"", "", true},
+		{"", "DG-POD-002", false},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.s); got != tt.match {
+			t.Errorf("Match(%q, %q) = %v, expected %v", tt.pattern, tt.s, got, tt.match)
+		}
+	}
+}
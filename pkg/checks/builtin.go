@@ -0,0 +1,217 @@
+package checks
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// The built-in checks. Each has a Rego counterpart with the same ID in the
+// default Rego library (internal/library); the two must report identical
+// findings.
+func init() {
+	Register(&builtinCheck{
+		id: "DG-POD-001",
+		metadata: Metadata{
+			Title:       "Container runs as root",
+			Description: "A container whose securityContext sets runAsUser to 0 runs as root, so a container breakout gives the attacker root on the node.",
+			Severity:    SeverityHigh,
+		},
+		kinds:    []string{"Pod"},
+		evaluate: checkRootUser,
+	})
+	Register(&builtinCheck{
+		id: "DG-POD-002",
+		metadata: Metadata{
+			Title:       "Privileged container",
+			Description: "Privileged containers run with all capabilities and full access to the host's devices, so a compromised container compromises the node.",
+			Severity:    SeverityCritical,
+		},
+		kinds:    []string{"Pod"},
+		evaluate: checkPrivileged,
+	})
+	Register(&builtinCheck{
+		id: "DG-POD-003",
+		metadata: Metadata{
+			Title:       "hostPath volume",
+			Description: "hostPath volumes expose the node's filesystem to the pod and can be used to escape the container or read other workloads' data.",
+			Severity:    SeverityMedium,
+		},
+		kinds:    []string{"Pod"},
+		evaluate: checkHostPath,
+	})
+	Register(&builtinCheck{
+		id: "DG-SVC-001",
+		metadata: Metadata{
+			Title:       "NodePort service",
+			Description: "NodePort services open a port on every node, bypassing load balancers and firewalls placed in front of the cluster.",
+			Severity:    SeverityMedium,
+		},
+		kinds:    []string{"Service"},
+		evaluate: checkServiceType(corev1.ServiceTypeNodePort, "Service uses NodePort which exposes ports on all nodes"),
+	})
+	Register(&builtinCheck{
+		id: "DG-SVC-002",
+		metadata: Metadata{
+			Title:       "LoadBalancer service",
+			Description: "LoadBalancer services usually get a public address, exposing the workload to the internet unless the load balancer is restricted.",
+			Severity:    SeverityMedium,
+		},
+		kinds:    []string{"Service"},
+		evaluate: checkServiceType(corev1.ServiceTypeLoadBalancer, "Service uses LoadBalancer which may expose the service to the internet"),
+	})
+	Register(&builtinCheck{
+		id: "DG-RBAC-001",
+		metadata: Metadata{
+			Title:       "Wildcard Role",
+			Description: "A Role granting every verb on every resource gives full control over its namespace, including its Secrets.",
+			Severity:    SeverityHigh,
+		},
+		kinds:    []string{"Role"},
+		evaluate: checkWildcardRole,
+	})
+	Register(&builtinCheck{
+		id: "DG-NS-001",
+		metadata: Metadata{
+			Title:       "Pod Security not enforced",
+			Description: "Namespaces without an enforced Pod Security level, or with the privileged level, admit pods with any security settings.",
+			Severity:    SeverityHigh,
+		},
+		kinds:    []string{"Namespace"},
+		evaluate: checkPodSecurityEnforced,
+	})
+}
+
+// builtinCheck is a check implemented by a function
+type builtinCheck struct {
+	id       string
+	metadata Metadata
+	kinds    []string
+	evaluate func(obj *unstructured.Unstructured) []Finding
+}
+
+func (c *builtinCheck) ID() string                                        { return c.id }
+func (c *builtinCheck) Metadata() Metadata                                { return c.metadata }
+func (c *builtinCheck) Kinds() []string                                   { return c.kinds }
+func (c *builtinCheck) Evaluate(obj *unstructured.Unstructured) []Finding { return c.evaluate(obj) }
+
+// convert converts an unstructured object to its typed form. Objects that do
+// not convert are skipped by the checks.
+func convert(obj *unstructured.Unstructured, into interface{}) bool {
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, into) == nil
+}
+
+// podFinding returns a finding on a container of a pod
+func podFinding(pod *corev1.Pod, container, reason string) Finding {
+	return Finding{Resource: "Pod", Namespace: pod.Namespace, Name: pod.Name, Container: container, Reason: reason}
+}
+
+// checkRootUser flags containers whose securityContext sets runAsUser to 0
+func checkRootUser(obj *unstructured.Unstructured) []Finding {
+	var pod corev1.Pod
+	if !convert(obj, &pod) {
+		return nil
+	}
+	var findings []Finding
+	for _, c := range pod.Spec.Containers {
+		if c.SecurityContext != nil && c.SecurityContext.RunAsUser != nil && *c.SecurityContext.RunAsUser == 0 {
+			findings = append(findings, podFinding(&pod, c.Name, fmt.Sprintf("Container '%s' runs as root user (uid 0)", c.Name)))
+		}
+	}
+	return findings
+}
+
+// checkPrivileged flags privileged containers
+func checkPrivileged(obj *unstructured.Unstructured) []Finding {
+	var pod corev1.Pod
+	if !convert(obj, &pod) {
+		return nil
+	}
+	var findings []Finding
+	for _, c := range pod.Spec.Containers {
+		if c.SecurityContext != nil && c.SecurityContext.Privileged != nil && *c.SecurityContext.Privileged {
+			findings = append(findings, podFinding(&pod, c.Name, fmt.Sprintf("Container '%s' is privileged", c.Name)))
+		}
+	}
+	return findings
+}
+
+// checkHostPath flags hostPath volumes, once for every container of the pod
+func checkHostPath(obj *unstructured.Unstructured) []Finding {
+	var pod corev1.Pod
+	if !convert(obj, &pod) {
+		return nil
+	}
+	var findings []Finding
+	for _, c := range pod.Spec.Containers {
+		for _, v := range pod.Spec.Volumes {
+			if v.HostPath != nil {
+				findings = append(findings, podFinding(&pod, c.Name, fmt.Sprintf("Container '%s' uses hostPath volume '%s'", c.Name, v.Name)))
+			}
+		}
+	}
+	return findings
+}
+
+// checkServiceType returns a check flagging services of the given type
+func checkServiceType(serviceType corev1.ServiceType, reason string) func(*unstructured.Unstructured) []Finding {
+	return func(obj *unstructured.Unstructured) []Finding {
+		var svc corev1.Service
+		if !convert(obj, &svc) || svc.Spec.Type != serviceType {
+			return nil
+		}
+		return []Finding{{Resource: "Service", Namespace: svc.Namespace, Name: svc.Name, Reason: reason}}
+	}
+}
+
+// checkWildcardRole flags roles with a rule granting every verb on every resource
+func checkWildcardRole(obj *unstructured.Unstructured) []Finding {
+	var role rbacv1.Role
+	if !convert(obj, &role) {
+		return nil
+	}
+	for _, rule := range role.Rules {
+		if contains(rule.Resources, "*") && contains(rule.Verbs, "*") {
+			return []Finding{{
+				Resource:  "Role",
+				Namespace: role.Namespace,
+				Name:      role.Name,
+				Reason:    "Role has wildcard resources and verbs which grants excessive permissions",
+			}}
+		}
+	}
+	return nil
+}
+
+// checkPodSecurityEnforced flags namespaces that do not enforce a Pod
+// Security level. Findings on a Namespace are reported under the namespace
+// itself.
+func checkPodSecurityEnforced(obj *unstructured.Unstructured) []Finding {
+	var ns corev1.Namespace
+	if !convert(obj, &ns) {
+		return nil
+	}
+	enforce := ns.Labels["pod-security.kubernetes.io/enforce"]
+	if enforce != "" && enforce != "privileged" {
+		return nil
+	}
+	return []Finding{{
+		Resource:  "Namespace",
+		Namespace: ns.Name,
+		Name:      ns.Name,
+		Reason:    "Namespace does not enforce PodSecurity standards or uses 'privileged' level",
+	}}
+}
+
+// contains reports whether list contains s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
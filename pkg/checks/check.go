@@ -0,0 +1,206 @@
+// Package checks defines the built-in checks of the audit and the registry
+// they are selected from. Programs embedding the audit can register their own
+// checks alongside the built-in ones:
+//
+//	func init() {
+//		checks.Register(myCheck{})
+//	}
+package checks
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+
+	"github.com/vibhordubey333/k8s-devguardian-ai/internal/auditor"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Finding is a single issue reported by a check
+type Finding = auditor.AuditFinding
+
+// Severity is the severity of a check and its findings
+type Severity = auditor.Severity
+
+// The severities, from the lowest to the highest
+const (
+	SeverityInfo     = auditor.SeverityInfo
+	SeverityLow      = auditor.SeverityLow
+	SeverityMedium   = auditor.SeverityMedium
+	SeverityHigh     = auditor.SeverityHigh
+	SeverityCritical = auditor.SeverityCritical
+)
+
+// Metadata describes a check
+type Metadata struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Severity    Severity `json:"severity"`
+}
+
+// Check is a single rule evaluated against every object of the kinds it
+// applies to
+type Check interface {
+	// ID returns the stable ID of the check, such as DG-POD-001
+	ID() string
+	// Metadata describes the check
+	Metadata() Metadata
+	// Kinds returns the kinds of object the check applies to, such as Pod
+	Kinds() []string
+	// Evaluate returns the findings for a single object. RuleID, Title and
+	// Severity are filled in from the check's ID and metadata when empty.
+	Evaluate(obj *unstructured.Unstructured) []Finding
+}
+
+// Registry is a set of checks keyed by ID
+type Registry struct {
+	mu     sync.RWMutex
+	checks map[string]Check
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{checks: make(map[string]Check)}
+}
+
+// defaultRegistry holds the built-in checks and those registered by
+// embedding programs
+var defaultRegistry = NewRegistry()
+
+// Default returns the registry the audit runs checks from
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Register adds a check to the default registry. It panics if a check with
+// the same ID is already registered.
+func Register(c Check) {
+	if err := defaultRegistry.Register(c); err != nil {
+		panic(err)
+	}
+}
+
+// Register adds a check to the registry
+func (r *Registry) Register(c Check) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if c.ID() == "" {
+		return fmt.Errorf("check has no ID")
+	}
+	if severity := c.Metadata().Severity; !severity.Valid() {
+		return fmt.Errorf("check %s has invalid severity %q", c.ID(), severity)
+	}
+	if _, exists := r.checks[c.ID()]; exists {
+		return fmt.Errorf("check %s is already registered", c.ID())
+	}
+	r.checks[c.ID()] = c
+	return nil
+}
+
+// Get returns the check with the given ID
+func (r *Registry) Get(id string) (Check, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.checks[id]
+	return c, ok
+}
+
+// Checks returns every registered check, sorted by ID
+func (r *Registry) Checks() []Check {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	checks := make([]Check, 0, len(r.checks))
+	for _, c := range r.checks {
+		checks = append(checks, c)
+	}
+	sort.Slice(checks, func(i, j int) bool { return checks[i].ID() < checks[j].ID() })
+	return checks
+}
+
+// Select returns the checks to run. With no enable patterns every check is
+// selected; otherwise only checks matching one of them. Checks matching a
+// disable pattern are then removed. Patterns are check IDs and may contain
+// wildcards, such as DG-POD-*. A pattern matching no check is an error, so
+// that typos do not silently disable nothing.
+func (r *Registry) Select(enable, disable []string) ([]Check, error) {
+	all := r.Checks()
+	for _, pattern := range append(append([]string{}, enable...), disable...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid check pattern %q: %w", pattern, err)
+		}
+		if !matchesAny(all, pattern) {
+			return nil, fmt.Errorf("no check matches %q", pattern)
+		}
+	}
+
+	var selected []Check
+	for _, c := range all {
+		if len(enable) > 0 && !matchesPattern(c.ID(), enable) {
+			continue
+		}
+		if matchesPattern(c.ID(), disable) {
+			continue
+		}
+		selected = append(selected, c)
+	}
+	return selected, nil
+}
+
+// matchesAny reports whether any check's ID matches pattern
+func matchesAny(checks []Check, pattern string) bool {
+	for _, c := range checks {
+		if matchesPattern(c.ID(), []string{pattern}) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPattern reports whether id matches any of the patterns
+func matchesPattern(id string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, id); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Run evaluates each object against the checks that apply to its kind. The
+// objects must be in their unstructured form with apiVersion and kind set.
+func Run(checks []Check, objects []map[string]interface{}) []Finding {
+	var findings []Finding
+	for _, obj := range objects {
+		u := &unstructured.Unstructured{Object: obj}
+		for _, c := range checks {
+			if !appliesTo(c, u.GetKind()) {
+				continue
+			}
+			meta := c.Metadata()
+			for _, f := range c.Evaluate(u) {
+				if f.RuleID == "" {
+					f.RuleID = c.ID()
+				}
+				if f.Title == "" {
+					f.Title = meta.Title
+				}
+				if f.Severity == "" {
+					f.Severity = meta.Severity
+				}
+				findings = append(findings, f)
+			}
+		}
+	}
+	return findings
+}
+
+// appliesTo reports whether c applies to objects of the given kind
+func appliesTo(c Check, kind string) bool {
+	for _, k := range c.Kinds() {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
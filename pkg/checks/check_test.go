@@ -0,0 +1,197 @@
+package checks
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestBuiltinChecks(t *testing.T) {
+	tests := []struct {
+		id      string
+		obj     map[string]interface{}
+		reasons []string
+	}{
+		{
+			id: "DG-POD-001",
+			obj: map[string]interface{}{
+				"apiVersion": "v1", "kind": "Pod",
+				"metadata": map[string]interface{}{"name": "web", "namespace": "default"},
+				"spec": map[string]interface{}{"containers": []interface{}{
+					map[string]interface{}{"name": "app", "securityContext": map[string]interface{}{"runAsUser": int64(0)}},
+					map[string]interface{}{"name": "sidecar", "securityContext": map[string]interface{}{"runAsUser": int64(1000)}},
+					map[string]interface{}{"name": "init"},
+				}},
+			},
+			reasons: []string{"Container 'app' runs as root user (uid 0)"},
+		},
+		{
+			id: "DG-POD-002",
+			obj: map[string]interface{}{
+				"apiVersion": "v1", "kind": "Pod",
+				"metadata": map[string]interface{}{"name": "web", "namespace": "default"},
+				"spec": map[string]interface{}{"containers": []interface{}{
+					map[string]interface{}{"name": "app", "securityContext": map[string]interface{}{"privileged": true}},
+					map[string]interface{}{"name": "sidecar", "securityContext": map[string]interface{}{"privileged": false}},
+				}},
+			},
+			reasons: []string{"Container 'app' is privileged"},
+		},
+		{
+			id: "DG-POD-003",
+			obj: map[string]interface{}{
+				"apiVersion": "v1", "kind": "Pod",
+				"metadata": map[string]interface{}{"name": "web", "namespace": "default"},
+				"spec": map[string]interface{}{
+					"containers": []interface{}{map[string]interface{}{"name": "app"}},
+					"volumes": []interface{}{
+						map[string]interface{}{"name": "logs", "hostPath": map[string]interface{}{"path": "/var/log"}},
+						map[string]interface{}{"name": "tmp", "emptyDir": map[string]interface{}{}},
+					},
+				},
+			},
+			reasons: []string{"Container 'app' uses hostPath volume 'logs'"},
+		},
+		{
+			id: "DG-SVC-001",
+			obj: map[string]interface{}{
+				"apiVersion": "v1", "kind": "Service",
+				"metadata": map[string]interface{}{"name": "web", "namespace": "default"},
+				"spec":     map[string]interface{}{"type": "NodePort"},
+			},
+			reasons: []string{"Service uses NodePort which exposes ports on all nodes"},
+		},
+		{
+			id: "DG-SVC-002",
+			obj: map[string]interface{}{
+				"apiVersion": "v1", "kind": "Service",
+				"metadata": map[string]interface{}{"name": "web", "namespace": "default"},
+				"spec":     map[string]interface{}{"type": "NodePort"},
+			},
+		},
+		{
+			id: "DG-RBAC-001",
+			obj: map[string]interface{}{
+				"apiVersion": "rbac.authorization.k8s.io/v1", "kind": "Role",
+				"metadata": map[string]interface{}{"name": "admin", "namespace": "default"},
+				"rules": []interface{}{
+					map[string]interface{}{"resources": []interface{}{"*"}, "verbs": []interface{}{"*"}},
+					map[string]interface{}{"resources": []interface{}{"*"}, "verbs": []interface{}{"*"}},
+				},
+			},
+			reasons: []string{"Role has wildcard resources and verbs which grants excessive permissions"},
+		},
+		{
+			id: "DG-NS-001",
+			obj: map[string]interface{}{
+				"apiVersion": "v1", "kind": "Namespace",
+				"metadata": map[string]interface{}{
+					"name":   "apps",
+					"labels": map[string]interface{}{"pod-security.kubernetes.io/enforce": "privileged"},
+				},
+			},
+			reasons: []string{"Namespace does not enforce PodSecurity standards or uses 'privileged' level"},
+		},
+		{
+			id: "DG-NS-001",
+			obj: map[string]interface{}{
+				"apiVersion": "v1", "kind": "Namespace",
+				"metadata": map[string]interface{}{
+					"name":   "apps",
+					"labels": map[string]interface{}{"pod-security.kubernetes.io/enforce": "baseline"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		check, ok := Default().Get(tt.id)
+		if !ok {
+			t.Fatalf("Check %s is not registered", tt.id)
+		}
+		var reasons []string
+		for _, f := range check.Evaluate(&unstructured.Unstructured{Object: tt.obj}) {
+			reasons = append(reasons, f.Reason)
+		}
+		if strings.Join(reasons, "\n") != strings.Join(tt.reasons, "\n") {
+			t.Errorf("%s: expected %q, got %q", tt.id, tt.reasons, reasons)
+		}
+	}
+}
+
+// stubCheck is a check for testing the registry
+type stubCheck struct{ id string }
+
+func (c stubCheck) ID() string         { return c.id }
+func (c stubCheck) Metadata() Metadata { return Metadata{Title: "Stub", Severity: "Low"} }
+func (c stubCheck) Kinds() []string    { return []string{"ConfigMap"} }
+func (c stubCheck) Evaluate(obj *unstructured.Unstructured) []Finding {
+	return []Finding{{Resource: obj.GetKind(), Name: obj.GetName(), Reason: "stub"}}
+}
+
+// severeCheck is a stub check with an invalid severity
+type severeCheck struct{ stubCheck }
+
+func (c severeCheck) Metadata() Metadata { return Metadata{Title: "Stub", Severity: "Severe"} }
+
+func TestRegistry_Select(t *testing.T) {
+	registry := NewRegistry()
+	for _, id := range []string{"DG-POD-001", "DG-POD-002", "DG-SVC-001", "ACME-001"} {
+		if err := registry.Register(stubCheck{id: id}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if err := registry.Register(stubCheck{id: "ACME-001"}); err == nil {
+		t.Errorf("Expected an error registering a duplicate ID")
+	}
+	if err := registry.Register(severeCheck{stubCheck{id: "ACME-002"}}); err == nil {
+		t.Errorf("Expected an error registering a check with an invalid severity")
+	}
+
+	tests := []struct {
+		enable, disable []string
+		expected        string
+		wantErr         bool
+	}{
+		{expected: "ACME-001,DG-POD-001,DG-POD-002,DG-SVC-001"},
+		{enable: []string{"DG-POD-*"}, expected: "DG-POD-001,DG-POD-002"},
+		{disable: []string{"DG-*"}, expected: "ACME-001"},
+		{enable: []string{"DG-POD-*", "ACME-001"}, disable: []string{"DG-POD-002"}, expected: "ACME-001,DG-POD-001"},
+		{disable: []string{"DG-POD-009"}, wantErr: true},
+		{enable: []string{"[invalid"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		selected, err := registry.Select(tt.enable, tt.disable)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Select(%v, %v): expected an error", tt.enable, tt.disable)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Select(%v, %v): expected no error, got %v", tt.enable, tt.disable, err)
+		}
+		var ids []string
+		for _, c := range selected {
+			ids = append(ids, c.ID())
+		}
+		if strings.Join(ids, ",") != tt.expected {
+			t.Errorf("Select(%v, %v) = %v, expected %s", tt.enable, tt.disable, ids, tt.expected)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	findings := Run([]Check{stubCheck{id: "ACME-001"}}, []map[string]interface{}{
+		{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "settings"}},
+		{"apiVersion": "v1", "kind": "Secret", "metadata": map[string]interface{}{"name": "token"}},
+	})
+	if len(findings) != 1 {
+		t.Fatalf("Expected 1 finding, got %v", findings)
+	}
+	f := findings[0]
+	if f.Name != "settings" || f.RuleID != "ACME-001" || f.Title != "Stub" || f.Severity != "Low" {
+		t.Errorf("Unexpected finding %+v", f)
+	}
+}